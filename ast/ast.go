@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"strings"
 
 	token "github.com/TusharAbhinav/monkey/token"
@@ -10,6 +11,8 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // position of the first character belonging to the node
+	End() token.Position // position immediately after the node
 }
 
 // Statement and Expression have been inherited from Node interface
@@ -37,10 +40,13 @@ type IfExpression struct {
 type BlockStatement struct {
 	Token      token.Token // the { token
 	Statements []Statement
+	Rbrace     token.Token // the closing } token
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position  { return endOf(bs.Rbrace.End, bs.Token.End) }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 	for _, s := range bs.Statements {
@@ -50,6 +56,16 @@ func (bs *BlockStatement) String() string {
 }
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Pos }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+	return nodeEnd(ie.Condition, ie.Token.End)
+}
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("if")
@@ -64,6 +80,103 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// WhileExpression implements Expression interface
+type WhileExpression struct {
+	Token     token.Token // the token.WHILE token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode()      {}
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) Pos() token.Position  { return we.Token.Pos }
+func (we *WhileExpression) End() token.Position {
+	if we.Body != nil {
+		return we.Body.End()
+	}
+	return nodeEnd(we.Condition, we.Token.End)
+}
+func (we *WhileExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("while")
+	out.WriteString(we.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(we.Body.String())
+	return out.String()
+}
+
+// ForExpression implements Expression interface. It is the C-style loop
+// for (init; condition; update) { body }, where each of the three clauses
+// may be left out.
+type ForExpression struct {
+	Token     token.Token // the token.FOR token
+	Init      Statement   // a *LetStatement or an *ExpressionStatement
+	Condition Expression
+	Update    Expression
+	Body      *BlockStatement
+}
+
+func (fe *ForExpression) expressionNode()      {}
+func (fe *ForExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForExpression) Pos() token.Position  { return fe.Token.Pos }
+func (fe *ForExpression) End() token.Position {
+	if fe.Body != nil {
+		return fe.Body.End()
+	}
+	return fe.Token.End
+}
+func (fe *ForExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (")
+	if fe.Init != nil {
+		out.WriteString(strings.TrimSuffix(fe.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fe.Condition != nil {
+		out.WriteString(fe.Condition.String())
+	}
+	out.WriteString("; ")
+	if fe.Update != nil {
+		out.WriteString(fe.Update.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fe.Body.String())
+	return out.String()
+}
+
+// ForInExpression implements Expression interface. It represents
+// for (value in iterable) { body } and for (key, value in iterable) { body }.
+type ForInExpression struct {
+	Token    token.Token // the token.FOR token
+	Key      *Identifier // nil when the loop has a single binding
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForInExpression) expressionNode()      {}
+func (fe *ForInExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForInExpression) Pos() token.Position  { return fe.Token.Pos }
+func (fe *ForInExpression) End() token.Position {
+	if fe.Body != nil {
+		return fe.Body.End()
+	}
+	return nodeEnd(fe.Iterable, fe.Token.End)
+}
+func (fe *ForInExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (")
+	if fe.Key != nil {
+		out.WriteString(fe.Key.String() + ", ")
+	}
+	out.WriteString(fe.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fe.Body.String())
+	return out.String()
+}
+
 // Root node
 // Program implements Node interface
 func (p *Program) TokenLiteral() string {
@@ -73,6 +186,18 @@ func (p *Program) TokenLiteral() string {
 		return ""
 	}
 }
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
 func (p *Program) String() string {
 	var out bytes.Buffer
 	for _, s := range p.Statements {
@@ -85,12 +210,23 @@ func (p *Program) String() string {
 
 type LetStatement struct {
 	Token token.Token // the token.LET token
-	Name  *Identifier //implements Expression interface
+	Name  Pattern     // a *BindingPattern for let x, or an array or hash pattern
 	Value Expression
+	Doc   *CommentGroup // the /// comments above the statement, or nil
 }
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Pos }
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	if ls.Name != nil {
+		return ls.Name.End()
+	}
+	return ls.Token.End
+}
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
@@ -103,6 +239,64 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// FunctionDeclaration implements Statement interface. fn name(params) { body }
+// binds the function to name in the enclosing block before any statement of
+// the block runs, so declared functions can call each other in any order.
+type FunctionDeclaration struct {
+	Token    token.Token // the token.FUNCTION token
+	Name     *Identifier
+	Function *FunctionLiteral
+	Doc      *CommentGroup // the /// comments above the declaration, or nil
+}
+
+func (fd *FunctionDeclaration) statementNode()       {}
+func (fd *FunctionDeclaration) TokenLiteral() string { return fd.Token.Literal }
+func (fd *FunctionDeclaration) Pos() token.Position  { return fd.Token.Pos }
+func (fd *FunctionDeclaration) End() token.Position  { return fd.Function.End() }
+func (fd *FunctionDeclaration) String() string {
+	var out bytes.Buffer
+	params := []string{}
+	for _, p := range fd.Function.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString(fd.TokenLiteral() + " ")
+	out.WriteString(fd.Name.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(fd.Function.Body.String())
+	return out.String()
+}
+
+// CommentGroup is a run of /// doc comments documenting the node that follows them
+type CommentGroup struct {
+	List []token.Token // the DOC_COMMENT tokens
+}
+
+func (cg *CommentGroup) Pos() token.Position { return cg.List[0].Pos }
+func (cg *CommentGroup) End() token.Position { return cg.List[len(cg.List)-1].End }
+
+// Text returns the documentation with one line per comment.
+func (cg *CommentGroup) Text() string {
+	if cg == nil {
+		return ""
+	}
+	lines := make([]string, len(cg.List))
+	for i, c := range cg.List {
+		lines[i] = c.Literal
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewCommentGroup groups the doc comments attached to a token, returning nil
+// when there are none.
+func NewCommentGroup(doc []token.Token) *CommentGroup {
+	if len(doc) == 0 {
+		return nil
+	}
+	return &CommentGroup{List: doc}
+}
+
 type ExpressionStatement struct {
 	Token      token.Token // the first token of the expression
 	Expression Expression
@@ -110,6 +304,8 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return es.Token.Pos }
+func (es *ExpressionStatement) End() token.Position  { return nodeEnd(es.Expression, es.Token.End) }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -124,6 +320,8 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Position  { return nodeEnd(rs.ReturnValue, rs.Token.End) }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(rs.TokenLiteral() + " ")
@@ -134,6 +332,52 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// BreakStatement implements Statement interface
+type BreakStatement struct {
+	Token token.Token // the token.BREAK token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Position  { return bs.Token.End }
+func (bs *BreakStatement) String() string       { return bs.TokenLiteral() + ";" }
+
+// ContinueStatement implements Statement interface
+type ContinueStatement struct {
+	Token token.Token // the token.CONTINUE token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Position  { return cs.Token.End }
+func (cs *ContinueStatement) String() string       { return cs.TokenLiteral() + ";" }
+
+// BadStatement is a placeholder for a statement that could not be parsed
+type BadStatement struct {
+	From token.Token // first token of the malformed statement
+	To   token.Token // last token skipped while recovering
+}
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return bs.From.Literal }
+func (bs *BadStatement) String() string       { return "<bad statement>" }
+func (bs *BadStatement) Pos() token.Position  { return bs.From.Pos }
+func (bs *BadStatement) End() token.Position  { return endOf(bs.To.End, bs.From.End) }
+
+// BadExpression is a placeholder for an expression that could not be parsed
+type BadExpression struct {
+	From token.Token // first token of the malformed expression
+	To   token.Token // token the parser was at when it gave up
+}
+
+func (be *BadExpression) expressionNode()      {}
+func (be *BadExpression) TokenLiteral() string { return be.From.Literal }
+func (be *BadExpression) String() string       { return "<bad expression>" }
+func (be *BadExpression) Pos() token.Position  { return be.From.Pos }
+func (be *BadExpression) End() token.Position  { return endOf(be.To.End, be.From.End) }
+
 // Identifier implements Expression interface
 type Identifier struct {
 	Token token.Token // the token.IDENT token
@@ -143,16 +387,96 @@ type Identifier struct {
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 func (i *Identifier) String() string       { return i.Value }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
+func (i *Identifier) End() token.Position  { return i.Token.End }
 
-// IntegerLiteral implements Expression interface
+// IntegerLiteral implements Expression interface. Literals that do not fit
+// in an int64 keep their value in Big and leave Value at zero.
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position  { return il.Token.End }
+
+// FloatLiteral implements Expression interface
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position  { return fl.Token.End }
+
+// RationalLiteral implements Expression interface. The literal 3r is the
+// exact number 3, so 1/3r divides 1 by it.
+type RationalLiteral struct {
+	Token token.Token
+	Value *big.Rat
+}
+
+func (rl *RationalLiteral) expressionNode()      {}
+func (rl *RationalLiteral) TokenLiteral() string { return rl.Token.Literal }
+func (rl *RationalLiteral) String() string       { return rl.Token.Literal }
+func (rl *RationalLiteral) Pos() token.Position  { return rl.Token.Pos }
+func (rl *RationalLiteral) End() token.Position  { return rl.Token.End }
+
+// DecimalLiteral implements Expression interface. 12.50d is stored as the
+// unscaled value 1250 with a scale of 2, keeping the trailing zero.
+type DecimalLiteral struct {
+	Token    token.Token
+	Unscaled *big.Int
+	Scale    int
+}
+
+func (dl *DecimalLiteral) expressionNode()      {}
+func (dl *DecimalLiteral) TokenLiteral() string { return dl.Token.Literal }
+func (dl *DecimalLiteral) String() string       { return dl.Token.Literal }
+func (dl *DecimalLiteral) Pos() token.Position  { return dl.Token.Pos }
+func (dl *DecimalLiteral) End() token.Position  { return dl.Token.End }
+
+// StringLiteral implements Expression interface
+type StringLiteral struct {
+	Token token.Token
+	Value string // the string with escape sequences decoded
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return quote(sl.Value) }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
+
+// quote returns s as a string literal, escaping the characters the lexer
+// requires to be escaped.
+func quote(s string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '\n':
+			out.WriteString(`\n`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		default:
+			out.WriteByte(ch)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
 
 // PrefixExpression implements Expression interface
 
@@ -165,6 +489,8 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
+func (pe *PrefixExpression) End() token.Position  { return nodeEnd(pe.Right, pe.Token.End) }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -184,6 +510,8 @@ type InfixExpression struct {
 
 func (oe *InfixExpression) expressionNode()      {}
 func (oe *InfixExpression) TokenLiteral() string { return oe.Token.Literal }
+func (oe *InfixExpression) Pos() token.Position  { return nodePos(oe.Left, oe.Token.Pos) }
+func (oe *InfixExpression) End() token.Position  { return nodeEnd(oe.Right, oe.Token.End) }
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -194,6 +522,47 @@ func (oe *InfixExpression) String() string {
 	return out.String()
 }
 
+// AssignExpression implements Expression interface. It represents x = value
+// as well as the compound forms x += value, x -= value and so on, whose
+// Operator is the whole token literal.
+type AssignExpression struct {
+	Token    token.Token // The operator token, e.g. = or +=
+	Target   Expression  // an *Identifier, *IndexExpression or *MemberExpression
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Pos() token.Position  { return nodePos(ae.Target, ae.Token.Pos) }
+func (ae *AssignExpression) End() token.Position  { return nodeEnd(ae.Value, ae.Token.End) }
+func (ae *AssignExpression) String() string {
+	return ae.Target.String() + " " + ae.Operator + " " + ae.Value.String()
+}
+
+// LogicalExpression implements Expression interface. It represents
+// a && b and a || b, whose right side is only evaluated when needed.
+type LogicalExpression struct {
+	Token    token.Token // The operator token, && or ||
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+func (le *LogicalExpression) expressionNode()      {}
+func (le *LogicalExpression) TokenLiteral() string { return le.Token.Literal }
+func (le *LogicalExpression) Pos() token.Position  { return nodePos(le.Left, le.Token.Pos) }
+func (le *LogicalExpression) End() token.Position  { return nodeEnd(le.Right, le.Token.End) }
+func (le *LogicalExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(le.Left.String())
+	out.WriteString(" " + le.Operator + " ")
+	out.WriteString(le.Right.String())
+	out.WriteString(")")
+	return out.String()
+}
+
 // Boolean implements Expression interface
 type Boolean struct {
 	Token token.Token
@@ -203,22 +572,40 @@ type Boolean struct {
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
 func (b *Boolean) String() string       { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Pos }
+func (b *Boolean) End() token.Position  { return b.Token.End }
 
 // FunctionLiteral implements Expression interface
 type FunctionLiteral struct {
-	Token      token.Token // The 'fn' token
-	Parameters []*Identifier
+	Token      token.Token // The 'fn' token, or the first token of an arrow function
+	Parameters []*Parameter
 	Body       *BlockStatement
+	Name       string // the name of a declared function or the let it was bound by, if any
+	Arrow      bool   // written as (params) => body or name => body
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	params := []string{}
 	for _, p := range fl.Parameters {
 		params = append(params, p.String())
 	}
+	if fl.Arrow {
+		out.WriteString("(")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString(") => ")
+		out.WriteString(fl.Body.String())
+		return out.String()
+	}
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
@@ -226,15 +613,42 @@ func (fl *FunctionLiteral) String() string {
 	out.WriteString(fl.Body.String())
 	return out.String()
 }
+
+// Parameter is a single parameter of a FunctionLiteral: a plain name, a name
+// with a default value used when the argument is left out, or ...name, which
+// collects the remaining positional arguments into an array.
+type Parameter struct {
+	Token   token.Token // the parameter name, or the ... of a rest parameter
+	Name    *Identifier
+	Default Expression // nil when the parameter has no default
+	Rest    bool
+}
+
+func (pa *Parameter) TokenLiteral() string { return pa.Token.Literal }
+func (pa *Parameter) Pos() token.Position  { return pa.Token.Pos }
+func (pa *Parameter) End() token.Position  { return nodeEnd(pa.Default, pa.Name.End()) }
+func (pa *Parameter) String() string {
+	switch {
+	case pa.Rest:
+		return "..." + pa.Name.String()
+	case pa.Default != nil:
+		return pa.Name.String() + " = " + pa.Default.String()
+	}
+	return pa.Name.String()
+}
+
 // CallExpression implements expression interface
 type CallExpression struct {
 	Token     token.Token // The '(' token
 	Function  Expression  // Identifier or FunctionLiteral
 	Arguments []Expression
+	Rparen    token.Token // The closing ')' token
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return nodePos(ce.Function, ce.Token.Pos) }
+func (ce *CallExpression) End() token.Position  { return endOf(ce.Rparen.End, ce.Token.End) }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 	args := []string{}
@@ -247,3 +661,219 @@ func (ce *CallExpression) String() string {
 	out.WriteString(")")
 	return out.String()
 }
+
+// PipeExpression implements Expression interface. left |> f(args) calls f
+// with left as its first argument, followed by args, and left |> h.f(args)
+// calls h.f the same way.
+type PipeExpression struct {
+	Token token.Token // the |> token
+	Left  Expression
+	Call  Expression // a *CallExpression or a *MethodCallExpression
+}
+
+func (pe *PipeExpression) expressionNode()      {}
+func (pe *PipeExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PipeExpression) Pos() token.Position  { return nodePos(pe.Left, pe.Token.Pos) }
+func (pe *PipeExpression) End() token.Position  { return pe.Call.End() }
+func (pe *PipeExpression) String() string {
+	return "(" + pe.Left.String() + " |> " + pe.Call.String() + ")"
+}
+
+// MemberExpression implements Expression interface. object.name reads the
+// value stored under the string key "name" of a hash.
+type MemberExpression struct {
+	Token    token.Token // the '.' token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) Pos() token.Position  { return nodePos(me.Object, me.Token.Pos) }
+func (me *MemberExpression) End() token.Position  { return me.Property.End() }
+func (me *MemberExpression) String() string {
+	return "(" + me.Object.String() + "." + me.Property.String() + ")"
+}
+
+// MethodCallExpression implements Expression interface. object.name(args)
+// calls the function stored under "name" when object is a hash that has
+// one, and name(object, args) otherwise.
+type MethodCallExpression struct {
+	Token     token.Token // the '.' token
+	Object    Expression
+	Method    *Identifier
+	Arguments []Expression
+	Rparen    token.Token // the closing ')' token
+}
+
+func (mc *MethodCallExpression) expressionNode()      {}
+func (mc *MethodCallExpression) TokenLiteral() string { return mc.Token.Literal }
+func (mc *MethodCallExpression) Pos() token.Position  { return nodePos(mc.Object, mc.Token.Pos) }
+func (mc *MethodCallExpression) End() token.Position  { return endOf(mc.Rparen.End, mc.Method.End()) }
+func (mc *MethodCallExpression) String() string {
+	args := []string{}
+	for _, a := range mc.Arguments {
+		args = append(args, a.String())
+	}
+	return mc.Object.String() + "." + mc.Method.String() + "(" + strings.Join(args, ", ") + ")"
+}
+
+// KeywordArgument implements Expression interface. It is a name: value
+// argument of a CallExpression, passed to the parameter with that name.
+type KeywordArgument struct {
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode()      {}
+func (ka *KeywordArgument) TokenLiteral() string { return ka.Name.TokenLiteral() }
+func (ka *KeywordArgument) Pos() token.Position  { return ka.Name.Pos() }
+func (ka *KeywordArgument) End() token.Position  { return nodeEnd(ka.Value, ka.Name.End()) }
+func (ka *KeywordArgument) String() string {
+	return ka.Name.String() + ": " + ka.Value.String()
+}
+
+// ArrayLiteral implements Expression interface
+type ArrayLiteral struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+	Rbracket token.Token // the closing ']' token
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position  { return endOf(al.Rbracket.End, al.Token.End) }
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+	return out.String()
+}
+
+// HashLiteral implements Expression interface
+type HashLiteral struct {
+	Token  token.Token // the '{' token
+	Pairs  []HashPair  // in source order
+	Rbrace token.Token // the closing '}' token
+}
+
+// HashPair is a single key: value entry of a HashLiteral
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position  { return endOf(hl.Rbrace.End, hl.Token.End) }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// IndexExpression implements Expression interface
+type IndexExpression struct {
+	Token    token.Token // the '[' token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Token // the closing ']' token
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return nodePos(ie.Left, ie.Token.Pos) }
+func (ie *IndexExpression) End() token.Position  { return endOf(ie.Rbracket.End, ie.Token.End) }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+	return out.String()
+}
+
+// SliceExpression implements Expression interface. It represents
+// left[low:high], where either bound may be nil.
+type SliceExpression struct {
+	Token    token.Token // the '[' token
+	Left     Expression
+	Low      Expression
+	High     Expression
+	Rbracket token.Token // the closing ']' token
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return nodePos(se.Left, se.Token.Pos) }
+func (se *SliceExpression) End() token.Position  { return endOf(se.Rbracket.End, se.Token.End) }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+	out.WriteString(":")
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
+// RangeExpression implements Expression interface. It represents
+// low..high, which leaves out high, and low..=high, which includes it.
+type RangeExpression struct {
+	Token     token.Token // the .. or ..= token
+	Low       Expression
+	High      Expression
+	Inclusive bool
+}
+
+func (re *RangeExpression) expressionNode()      {}
+func (re *RangeExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RangeExpression) Pos() token.Position  { return nodePos(re.Low, re.Token.Pos) }
+func (re *RangeExpression) End() token.Position  { return nodeEnd(re.High, re.Token.End) }
+func (re *RangeExpression) String() string {
+	return "(" + re.Low.String() + re.Token.Literal + re.High.String() + ")"
+}
+
+// Nodes left incomplete by a parse error may be missing children, so the
+// position helpers below fall back to a position the node does have.
+
+func nodePos(n Node, fallback token.Position) token.Position {
+	if n == nil {
+		return fallback
+	}
+	return n.Pos()
+}
+
+func nodeEnd(n Node, fallback token.Position) token.Position {
+	if n == nil {
+		return fallback
+	}
+	return n.End()
+}
+
+func endOf(pos, fallback token.Position) token.Position {
+	if !pos.IsValid() {
+		return fallback
+	}
+	return pos
+}
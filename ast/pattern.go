@@ -0,0 +1,170 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	token "github.com/TusharAbhinav/monkey/token"
+)
+
+// ============================
+// MATCH EXPRESSIONS AND PATTERNS
+// ============================
+
+// Pattern describes the shape a value must have for a match arm to apply,
+// binding parts of the value to names on the way.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// MatchExpression implements Expression interface. It represents
+// match (subject) { pattern => body, pattern if guard => body, ... }.
+type MatchExpression struct {
+	Token   token.Token // the token.MATCH token
+	Subject Expression
+	Arms    []*MatchArm
+	Rbrace  token.Token // the closing '}' token
+}
+
+// MatchArm is a single pattern => body entry of a MatchExpression. Body is
+// an Expression, or a *BlockStatement when the arm starts with {.
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Expression // nil when the arm has no if guard
+	Body    Node
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() token.Position  { return me.Token.Pos }
+func (me *MatchExpression) End() token.Position  { return endOf(me.Rbrace.End, me.Token.End) }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+	out.WriteString("match")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" {")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+func (ma *MatchArm) TokenLiteral() string { return ma.Pattern.TokenLiteral() }
+func (ma *MatchArm) Pos() token.Position  { return ma.Pattern.Pos() }
+func (ma *MatchArm) End() token.Position  { return nodeEnd(ma.Body, ma.Pattern.End()) }
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+	return out.String()
+}
+
+// LiteralPattern matches values equal to a literal, such as 0, -1.5,
+// "text" or true.
+type LiteralPattern struct {
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Value.TokenLiteral() }
+func (lp *LiteralPattern) Pos() token.Position  { return lp.Value.Pos() }
+func (lp *LiteralPattern) End() token.Position  { return lp.Value.End() }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// BindingPattern matches any value and binds it to Name.
+type BindingPattern struct {
+	Name *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Name.TokenLiteral() }
+func (bp *BindingPattern) Pos() token.Position  { return bp.Name.Pos() }
+func (bp *BindingPattern) End() token.Position  { return bp.Name.End() }
+func (bp *BindingPattern) String() string       { return bp.Name.String() }
+
+// WildcardPattern, written _, matches any value without binding it.
+type WildcardPattern struct {
+	Token token.Token // the _ identifier token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) Pos() token.Position  { return wp.Token.Pos }
+func (wp *WildcardPattern) End() token.Position  { return wp.Token.End }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// ArrayPattern matches arrays element by element. Without Rest the array
+// must have exactly as many elements as the pattern; [first, ...rest]
+// matches arrays with at least one element and binds the others to rest.
+type ArrayPattern struct {
+	Token    token.Token // the '[' token
+	Elements []Pattern
+	Rest     *Identifier // nil when the pattern has no ...rest
+	Rbracket token.Token // the closing ']' token
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) Pos() token.Position  { return ap.Token.Pos }
+func (ap *ArrayPattern) End() token.Position  { return endOf(ap.Rbracket.End, ap.Token.End) }
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// HashPattern matches hashes that have all of its keys, with values
+// matching the pattern given for each key. Other keys are ignored. A bare
+// name as key stands for the string key of that name, and {name} on its own
+// is short for {name: name}.
+type HashPattern struct {
+	Token  token.Token // the '{' token
+	Pairs  []HashPatternPair
+	Rbrace token.Token // the closing '}' token
+}
+
+// HashPatternPair is a single key: pattern entry of a HashPattern
+type HashPatternPair struct {
+	Key   Expression // a literal or an *Identifier
+	Value Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) Pos() token.Position  { return hp.Token.Pos }
+func (hp *HashPattern) End() token.Position  { return endOf(hp.Rbrace.End, hp.Token.End) }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for _, pair := range hp.Pairs {
+		if pair.isShorthand() {
+			pairs = append(pairs, pair.Key.String())
+			continue
+		}
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// isShorthand reports whether the pair can be written as {name}.
+func (pair HashPatternPair) isShorthand() bool {
+	key, ok := pair.Key.(*Identifier)
+	if !ok {
+		return false
+	}
+	value, ok := pair.Value.(*BindingPattern)
+	return ok && value.Name.Value == key.Value
+}
@@ -0,0 +1,62 @@
+package diagnostic
+
+import (
+	"fmt"
+
+	token "github.com/TusharAbhinav/monkey/token"
+)
+
+// Severity tells how serious a diagnostic is.
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Note:
+		return "note"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Span is the half-open range of source text [Start, End) a diagnostic points at.
+type Span struct {
+	Start token.Position
+	End   token.Position
+}
+
+// SpanOf returns the span covered by tok.
+func SpanOf(tok token.Token) Span {
+	return Span{Start: tok.Pos, End: tok.End}
+}
+
+// Diagnostic is a single message about the source, located by its Span.
+type Diagnostic struct {
+	Severity Severity
+	Span     Span
+	Code     string   // stable identifier of the kind of problem, e.g. P0001
+	Message  string   // one line summary shown next to the severity
+	Notes    []string // extra explanation printed below the snippet
+}
+
+// Error returns the diagnostic on a single line, prefixed by its position.
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s", d.Span.Start, d.header())
+}
+
+// header returns the "error[P0001]: message" line of the diagnostic.
+func (d Diagnostic) header() string {
+	if d.Code == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+}
@@ -0,0 +1,96 @@
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Render writes d to w together with the source line it points at, with the
+// offending span underlined:
+//
+//	error[P0001]: expected next token to be ), got ; instead
+//	 --> repl:1:11
+//	  |
+//	1 | let x = (5;
+//	  |           ^
+//
+// source must be the full text the span's positions refer to.
+func Render(w io.Writer, source string, d Diagnostic) {
+	start := d.Span.Start
+	fmt.Fprintln(w, d.header())
+	if !start.IsValid() {
+		renderNotes(w, "", d.Notes)
+		return
+	}
+
+	line := sourceLine(source, start.Offset)
+	lineNo := strconv.Itoa(start.Line)
+	gutter := strings.Repeat(" ", len(lineNo))
+
+	fmt.Fprintf(w, "%s--> %s\n", gutter, start)
+	fmt.Fprintf(w, "%s |\n", gutter)
+	fmt.Fprintf(w, "%s | %s\n", lineNo, line)
+	fmt.Fprintf(w, "%s | %s\n", gutter, underline(line, d.Span))
+	renderNotes(w, gutter, d.Notes)
+}
+
+// RenderAll renders each diagnostic in turn, separated by blank lines.
+func RenderAll(w io.Writer, source string, diags []Diagnostic) {
+	for i, d := range diags {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		Render(w, source, d)
+	}
+}
+
+func renderNotes(w io.Writer, gutter string, notes []string) {
+	for _, note := range notes {
+		fmt.Fprintf(w, "%s = note: %s\n", gutter, note)
+	}
+}
+
+// sourceLine returns the line of source containing offset, without its line break.
+func sourceLine(source string, offset int) string {
+	if offset > len(source) {
+		offset = len(source)
+	}
+	start := strings.LastIndexByte(source[:offset], '\n') + 1
+	end := strings.IndexByte(source[offset:], '\n')
+	if end < 0 {
+		end = len(source)
+	} else {
+		end += offset
+	}
+	return strings.TrimSuffix(source[start:end], "\r")
+}
+
+// underline returns the ^~~~ marker for the part of span on line, copying
+// tabs from line so the marker stays aligned with the text above it.
+// Columns count runes; spans running past the end of the line are cut off there.
+func underline(line string, span Span) string {
+	runes := []rune(line)
+	column := span.Start.Column - 1
+	if column > len(runes) {
+		column = len(runes)
+	}
+	var out strings.Builder
+	for _, r := range runes[:column] {
+		if r == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+	}
+	width := len(runes) - column
+	if span.End.Line == span.Start.Line && span.End.Column-span.Start.Column < width {
+		width = span.End.Column - span.Start.Column
+	}
+	out.WriteByte('^')
+	if width > 1 {
+		out.WriteString(strings.Repeat("~", width-1))
+	}
+	return out.String()
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TusharAbhinav/monkey/diagnostic"
+	token "github.com/TusharAbhinav/monkey/token"
+)
+
+func TestRender(t *testing.T) {
+	source := "let x = 1;\nlet total = x +* 2;\n"
+	d := diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span: diagnostic.Span{
+			Start: token.Position{Filename: "main.mk", Offset: 23, Line: 2, Column: 13},
+			End:   token.Position{Filename: "main.mk", Offset: 26, Line: 2, Column: 16},
+		},
+		Code:    "P0002",
+		Message: "no prefix parse function for * found",
+		Notes:   []string{"remove one of the operators"},
+	}
+	expected := `error[P0002]: no prefix parse function for * found
+ --> main.mk:2:13
+  |
+2 | let total = x +* 2;
+  |             ^~~
+  = note: remove one of the operators
+`
+	var out bytes.Buffer
+	diagnostic.Render(&out, source, d)
+	if out.String() != expected {
+		t.Errorf("wrong rendering.\nexpected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestRenderKeepsTabsAligned(t *testing.T) {
+	source := "\tfoo(;"
+	d := diagnostic.Diagnostic{
+		Severity: diagnostic.Warning,
+		Span: diagnostic.Span{
+			Start: token.Position{Offset: 5, Line: 1, Column: 6},
+			End:   token.Position{Offset: 6, Line: 1, Column: 7},
+		},
+		Message: "unexpected ;",
+	}
+	expected := "warning: unexpected ;\n --> 1:6\n  |\n1 | \tfoo(;\n  | \t    ^\n"
+	var out bytes.Buffer
+	diagnostic.Render(&out, source, d)
+	if out.String() != expected {
+		t.Errorf("wrong rendering.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestRenderClipsSpanToLine(t *testing.T) {
+	source := "if (x\n"
+	d := diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span: diagnostic.Span{
+			Start: token.Position{Offset: 3, Line: 1, Column: 4},
+			End:   token.Position{Offset: 6, Line: 2, Column: 1},
+		},
+		Message: "unclosed (",
+	}
+	expected := "error: unclosed (\n --> 1:4\n  |\n1 | if (x\n  |    ^~\n"
+	var out bytes.Buffer
+	diagnostic.Render(&out, source, d)
+	if out.String() != expected {
+		t.Errorf("wrong rendering.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestRenderCountsColumnsInCharacters(t *testing.T) {
+	source := `let café = "naïve" + ;`
+	d := diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span: diagnostic.Span{
+			Start: token.Position{Offset: 12, Line: 1, Column: 12},
+			End:   token.Position{Offset: 20, Line: 1, Column: 19},
+		},
+		Message: "bad string",
+	}
+	expected := "error: bad string\n --> 1:12\n  |\n1 | let café = \"naïve\" + ;\n  |            ^~~~~~~\n"
+	var out bytes.Buffer
+	diagnostic.Render(&out, source, d)
+	if out.String() != expected {
+		t.Errorf("wrong rendering.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestDiagnosticError(t *testing.T) {
+	d := diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span:     diagnostic.Span{Start: token.Position{Filename: "a.mk", Offset: 4, Line: 1, Column: 5}},
+		Code:     "P0001",
+		Message:  "expected next token to be IDENT, got = instead",
+	}
+	expected := "a.mk:1:5: error[P0001]: expected next token to be IDENT, got = instead"
+	if d.Error() != expected {
+		t.Errorf("wrong message. expected=%q, got=%q", expected, d.Error())
+	}
+}
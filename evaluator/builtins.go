@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"unicode/utf8"
+
+	"github.com/TusharAbhinav/monkey/object"
+)
+
+// builtins are the functions available in every program without being defined.
+// Identifiers bound in the environment shadow them.
+var builtins = map[string]*object.Builtin{
+	"len": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		switch arg := args[0].(type) {
+		case *object.String:
+			// strings are iterated and sliced by runes, so they are counted by runes too
+			return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+		case *object.Array:
+			return &object.Integer{Value: int64(len(arg.Elements))}
+		case *object.Hash:
+			return &object.Integer{Value: int64(len(arg.Order))}
+		case *object.Range:
+			return integerObject(arg.Len())
+		default:
+			return newError("argument to `len` not supported, got %s", args[0].Type())
+		}
+	}},
+	"first": {Fn: func(args ...object.Object) object.Object {
+		array, err := arrayArgument("first", 1, args)
+		if err != nil {
+			return err
+		}
+		if len(array.Elements) > 0 {
+			return array.Elements[0]
+		}
+		return NULL
+	}},
+	"last": {Fn: func(args ...object.Object) object.Object {
+		array, err := arrayArgument("last", 1, args)
+		if err != nil {
+			return err
+		}
+		if length := len(array.Elements); length > 0 {
+			return array.Elements[length-1]
+		}
+		return NULL
+	}},
+	"rest": {Fn: func(args ...object.Object) object.Object {
+		array, err := arrayArgument("rest", 1, args)
+		if err != nil {
+			return err
+		}
+		if length := len(array.Elements); length > 0 {
+			newElements := make([]object.Object, length-1)
+			copy(newElements, array.Elements[1:length])
+			return &object.Array{Elements: newElements}
+		}
+		return NULL
+	}},
+	"push": {Fn: func(args ...object.Object) object.Object {
+		array, err := arrayArgument("push", 2, args)
+		if err != nil {
+			return err
+		}
+		length := len(array.Elements)
+		newElements := make([]object.Object, length+1)
+		copy(newElements, array.Elements)
+		newElements[length] = args[1]
+		return &object.Array{Elements: newElements}
+	}},
+	"range": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 && len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+		}
+		bounds := make([]int64, len(args))
+		for i, arg := range args {
+			integer, ok := arg.(*object.Integer)
+			if !ok {
+				return newError("arguments to `range` must be INTEGER, got %s", arg.Type())
+			}
+			bounds[i] = integer.Value
+		}
+		if len(bounds) == 1 {
+			return &object.Range{Start: 0, End: bounds[0]}
+		}
+		return &object.Range{Start: bounds[0], End: bounds[1]}
+	}},
+}
+
+// arrayArgument checks that a builtin got want arguments, the first being an array.
+func arrayArgument(name string, want int, args []object.Object) (*object.Array, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return array, nil
+}
@@ -0,0 +1,1088 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/TusharAbhinav/monkey/ast"
+	"github.com/TusharAbhinav/monkey/object"
+)
+
+// There is only ever one true, one false and one null, so the evaluator
+// hands out references to these instead of allocating new objects.
+var (
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
+)
+
+// ============================
+// EVALUATION ENTRY POINT
+// ============================
+
+// Eval evaluates node in env and returns the resulting object.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		return evalProgram(node, env)
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+	case *ast.ReturnStatement:
+		val := Eval(node.ReturnValue, env)
+		if isSignal(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	case *ast.LetStatement:
+		val := Eval(node.Value, env)
+		if isSignal(val) {
+			return val
+		}
+		if err := bindPattern(node.Name, val, env); err != nil {
+			return err
+		}
+	case *ast.FunctionDeclaration:
+		// already bound by hoistFunctions when its block was entered
+		return nil
+	case *ast.BadStatement:
+		return newError("cannot evaluate malformed statement at %s", node.Pos())
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInteger{Value: node.Big}
+		}
+		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.RationalLiteral:
+		return &object.Rational{Value: node.Value}
+	case *ast.DecimalLiteral:
+		return &object.Decimal{Unscaled: node.Unscaled, Scale: node.Scale}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isSignal(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+	case *ast.InfixExpression:
+		left := Eval(node.Left, env)
+		if isSignal(left) {
+			return left
+		}
+		right := Eval(node.Right, env)
+		if isSignal(right) {
+			return right
+		}
+		return evalInfixExpression(node.Operator, left, right)
+	case *ast.LogicalExpression:
+		return evalLogicalExpression(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+	case *ast.WhileExpression:
+		return evalWhileExpression(node, env)
+	case *ast.ForExpression:
+		return evalForExpression(node, env)
+	case *ast.ForInExpression:
+		return evalForInExpression(node, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
+	case *ast.FunctionLiteral:
+		return &object.Function{Name: node.Name, Parameters: node.Parameters, Body: node.Body, Env: env}
+	case *ast.BadExpression:
+		return newError("cannot evaluate malformed expression at %s", node.Pos())
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isSignal(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isSignal(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isSignal(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.RangeExpression:
+		return evalRangeExpression(node, env)
+	case *ast.CallExpression:
+		return evalCallExpression(node, nil, env)
+	case *ast.MemberExpression:
+		receiver := Eval(node.Object, env)
+		if isSignal(receiver) {
+			return receiver
+		}
+		return evalMemberExpression(receiver, node.Property.Value)
+	case *ast.MethodCallExpression:
+		return evalMethodCallExpression(node, nil, env)
+	case *ast.PipeExpression:
+		left := Eval(node.Left, env)
+		if isSignal(left) {
+			return left
+		}
+		if call, ok := node.Call.(*ast.MethodCallExpression); ok {
+			return evalMethodCallExpression(call, left, env)
+		}
+		return evalCallExpression(node.Call.(*ast.CallExpression), left, env)
+	}
+
+	return nil
+}
+
+// ============================
+// STATEMENT EVALUATION
+// ============================
+
+// evalProgram evaluates top-level statements, unwrapping return values.
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	hoistFunctions(program.Statements, env)
+	var result object.Object
+	for _, statement := range program.Statements {
+		result = Eval(statement, env)
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+	return result
+}
+
+// evalBlockStatement evaluates a block, leaving return values wrapped so that
+// they keep propagating out of nested blocks. Break and continue propagate
+// the same way up to their loop. A block that produces no value evaluates to
+// null.
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	hoistFunctions(block.Statements, env)
+	var result object.Object
+	for _, statement := range block.Statements {
+		result = Eval(statement, env)
+		if isSignal(result) {
+			return result
+		}
+	}
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+// hoistFunctions binds the functions declared among statements before any
+// of them runs, so that they can call each other whatever their order.
+func hoistFunctions(statements []ast.Statement, env *object.Environment) {
+	for _, statement := range statements {
+		if decl, ok := statement.(*ast.FunctionDeclaration); ok {
+			env.Set(decl.Name.Value, &object.Function{
+				Name:       decl.Name.Value,
+				Parameters: decl.Function.Parameters,
+				Body:       decl.Function.Body,
+				Env:        env,
+			})
+		}
+	}
+}
+
+// ============================
+// EXPRESSION EVALUATION
+// ============================
+
+// evalIdentifier resolves an identifier against the environment and then the builtins.
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+	return newError("identifier not found: %s", node.Value)
+}
+
+// evalExpressions evaluates expressions left to right, stopping at the first
+// error, return value, break or continue.
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	result := []object.Object{}
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if isSignal(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+	return result
+}
+
+// evalPrefixExpression evaluates prefix operators like ! and -.
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalBitwiseNotOperatorExpression(right)
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+func evalBangOperatorExpression(right object.Object) object.Object {
+	switch right {
+	case TRUE:
+		return FALSE
+	case FALSE:
+		return TRUE
+	case NULL:
+		return TRUE
+	default:
+		return FALSE
+	}
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return integerObject(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInteger:
+		return integerObject(new(big.Int).Neg(right.Value))
+	case *object.Rational:
+		return &object.Rational{Value: new(big.Rat).Neg(right.Value)}
+	case *object.Decimal:
+		return &object.Decimal{Unscaled: new(big.Int).Neg(right.Unscaled), Scale: right.Scale}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError("unknown operator: -%s", right.Type())
+	}
+}
+
+// evalBitwiseNotOperatorExpression evaluates ~x, which flips every bit of an integer.
+func evalBitwiseNotOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInteger:
+		return integerObject(new(big.Int).Not(right.Value))
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
+}
+
+// evalInfixExpression evaluates binary operators on already evaluated operands.
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case isExact(left) && isExact(right):
+		return evalExactInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		// at least one side is a float, so the other one is converted
+		return evalFloatInfixExpression(operator, left, right)
+	case isExact(left) && isNumber(right), isNumber(left) && isExact(right):
+		// a float would silently round the exact side
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalLogicalExpression evaluates && and ||, only evaluating the right side
+// when the left side does not decide the result on its own.
+func evalLogicalExpression(node *ast.LogicalExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isSignal(left) {
+		return left
+	}
+	switch {
+	case node.Operator == "&&" && !isTruthy(left):
+		return FALSE
+	case node.Operator == "||" && isTruthy(left):
+		return TRUE
+	}
+	right := Eval(node.Right, env)
+	if isSignal(right) {
+		return right
+	}
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer).Value
+	rightVal := right.(*object.Integer).Value
+	switch operator {
+	case "+", "-", "*":
+		if result, ok := checkedIntegerOp(operator, leftVal, rightVal); ok {
+			return &object.Integer{Value: result}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "**", "<<", ">>":
+		// these overflow too easily to check, so they always go through big.Int
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalFloatInfixExpression evaluates arithmetic where at least one operand
+// is a float; integer operands are converted to float first.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalIndexExpression evaluates left[index].
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+// evalArrayIndexExpression returns the element at index, or null when index is out of range.
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	idx := index.(*object.Integer).Value
+	if idx < 0 || idx >= int64(len(elements)) {
+		return NULL
+	}
+	return elements[idx]
+}
+
+// evalSliceExpression evaluates left[low:high] on arrays and strings. A
+// negative bound counts from the end, a missing one stands for the start or
+// the end, and bounds past either end are clamped, so slicing never fails
+// on the bounds. Strings are sliced by runes.
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isSignal(left) {
+		return left
+	}
+	bounds := make([]*int64, 2)
+	for i, bound := range []ast.Expression{node.Low, node.High} {
+		if bound == nil {
+			continue
+		}
+		value := Eval(bound, env)
+		if isSignal(value) {
+			return value
+		}
+		integer, ok := value.(*object.Integer)
+		if !ok {
+			return newError("slice bounds must be INTEGER, got %s", value.Type())
+		}
+		bounds[i] = &integer.Value
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		low, high := sliceBounds(bounds[0], bounds[1], len(left.Elements))
+		elements := make([]object.Object, high-low)
+		copy(elements, left.Elements[low:high])
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		low, high := sliceBounds(bounds[0], bounds[1], len(runes))
+		return &object.String{Value: string(runes[low:high])}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceBounds resolves optional and negative slice bounds against length.
+// The results satisfy 0 <= low <= high <= length.
+func sliceBounds(low, high *int64, length int) (int, int) {
+	resolve := func(bound *int64, missing int) int {
+		if bound == nil {
+			return missing
+		}
+		i := *bound
+		if i < 0 {
+			i += int64(length)
+		}
+		return int(min(max(i, 0), int64(length)))
+	}
+	l := resolve(low, 0)
+	h := resolve(high, length)
+	return l, max(l, h)
+}
+
+// evalRangeExpression evaluates low..high and low..=high to a lazy range.
+func evalRangeExpression(node *ast.RangeExpression, env *object.Environment) object.Object {
+	low := Eval(node.Low, env)
+	if isSignal(low) {
+		return low
+	}
+	high := Eval(node.High, env)
+	if isSignal(high) {
+		return high
+	}
+	start, startOK := low.(*object.Integer)
+	end, endOK := high.(*object.Integer)
+	if !startOK || !endOK {
+		return newError("range bounds must be INTEGER, got %s%s%s", low.Type(), node.Token.Literal, high.Type())
+	}
+	return &object.Range{Start: start.Value, End: end.Value, Inclusive: node.Inclusive}
+}
+
+// evalHashIndexExpression returns the value stored under index, or null when there is none.
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+	pair, ok := hash.(*object.Hash).Get(key.HashKey())
+	if !ok {
+		return NULL
+	}
+	return pair.Value
+}
+
+// evalMemberExpression returns the value stored under the string key name
+// of a hash, or null when there is none.
+func evalMemberExpression(obj object.Object, name string) object.Object {
+	if obj.Type() != object.HASH_OBJ {
+		return newError("cannot access member %s of %s", name, obj.Type())
+	}
+	return evalHashIndexExpression(obj, &object.String{Value: name})
+}
+
+// evalHashLiteral evaluates the pairs of a hash literal in source order.
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isSignal(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+		value := Eval(pair.Value, env)
+		if isSignal(value) {
+			return value
+		}
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+	return hash
+}
+
+// evalIfExpression evaluates the consequence or alternative depending on the condition.
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+	if isSignal(condition) {
+		return condition
+	}
+	if isTruthy(condition) {
+		return Eval(ie.Consequence, env)
+	} else if ie.Alternative != nil {
+		return Eval(ie.Alternative, env)
+	}
+	return NULL
+}
+
+// ============================
+// LOOPS
+// ============================
+
+// evalWhileExpression runs the body for as long as the condition is truthy.
+// Loops evaluate to null.
+func evalWhileExpression(node *ast.WhileExpression, env *object.Environment) object.Object {
+	for {
+		condition := Eval(node.Condition, env)
+		if isSignal(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+		if result, done := evalLoopBody(node.Body, env); done {
+			return result
+		}
+	}
+}
+
+// evalForExpression runs a C-style for loop. The variables declared by its
+// init clause live in a scope of their own that ends with the loop.
+func evalForExpression(node *ast.ForExpression, env *object.Environment) object.Object {
+	loopEnv := object.NewEnclosedEnvironment(env)
+	if node.Init != nil {
+		if init := Eval(node.Init, loopEnv); isSignal(init) {
+			return init
+		}
+	}
+	for {
+		if node.Condition != nil {
+			condition := Eval(node.Condition, loopEnv)
+			if isSignal(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return NULL
+			}
+		}
+		if result, done := evalLoopBody(node.Body, loopEnv); done {
+			return result
+		}
+		if node.Update != nil {
+			if update := Eval(node.Update, loopEnv); isSignal(update) {
+				return update
+			}
+		}
+	}
+}
+
+// evalForInExpression runs the body once for every entry of an iterable.
+// Each iteration binds its variables in a fresh scope, so closures created
+// in the body keep the values of their own iteration. A loop with a single
+// variable binds the keys of hashes and the values of everything else.
+func evalForInExpression(node *ast.ForInExpression, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isSignal(iterable) {
+		return iterable
+	}
+	collection, ok := iterable.(object.Iterable)
+	if !ok {
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+	_, isHash := iterable.(*object.Hash)
+
+	iterator := collection.Iterate()
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			return NULL
+		}
+		iterationEnv := object.NewEnclosedEnvironment(env)
+		switch {
+		case node.Key != nil:
+			iterationEnv.Set(node.Key.Value, key)
+			iterationEnv.Set(node.Value.Value, value)
+		case isHash:
+			iterationEnv.Set(node.Value.Value, key)
+		default:
+			iterationEnv.Set(node.Value.Value, value)
+		}
+		if result, done := evalLoopBody(node.Body, iterationEnv); done {
+			return result
+		}
+	}
+}
+
+// evalLoopBody runs one iteration of a loop body and reports whether the
+// loop is done, together with the value the loop evaluates to in that case.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+	switch {
+	case result == BREAK:
+		return NULL, true
+	case result == CONTINUE:
+		return nil, false
+	case isSignal(result):
+		return result, true
+	}
+	return nil, false
+}
+
+// ============================
+// ASSIGNMENT
+// ============================
+
+// evalAssignExpression evaluates x = value, xs[i] = value, p.x = value and
+// their compound forms, and returns the value that was stored.
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		var current object.Object
+		if node.Operator != "=" {
+			current = evalIdentifier(target, env)
+			if isError(current) {
+				return current
+			}
+		}
+		val := evalAssignedValue(node, current, env)
+		if isSignal(val) {
+			return val
+		}
+		if !env.Assign(target.Value, val) {
+			return newError("assignment to undeclared identifier: %s", target.Value)
+		}
+		return val
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isSignal(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isSignal(index) {
+			return index
+		}
+		var current object.Object
+		if node.Operator != "=" {
+			current = evalIndexExpression(left, index)
+			if isError(current) {
+				return current
+			}
+		}
+		val := evalAssignedValue(node, current, env)
+		if isSignal(val) {
+			return val
+		}
+		return evalIndexAssignment(left, index, val)
+	case *ast.MemberExpression:
+		left := Eval(target.Object, env)
+		if isSignal(left) {
+			return left
+		}
+		if left.Type() != object.HASH_OBJ {
+			return newError("cannot assign to member %s of %s", target.Property.Value, left.Type())
+		}
+		var current object.Object
+		if node.Operator != "=" {
+			current = evalMemberExpression(left, target.Property.Value)
+			if isError(current) {
+				return current
+			}
+		}
+		val := evalAssignedValue(node, current, env)
+		if isSignal(val) {
+			return val
+		}
+		return evalIndexAssignment(left, &object.String{Value: target.Property.Value}, val)
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+// evalAssignedValue evaluates the right side of an assignment. For compound
+// assignments such as x += 1 it is combined with the current value.
+func evalAssignedValue(node *ast.AssignExpression, current object.Object, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isSignal(val) || node.Operator == "=" {
+		return val
+	}
+	operator := strings.TrimSuffix(node.Operator, "=")
+	return evalInfixExpression(operator, current, val)
+}
+
+// evalIndexAssignment stores val at left[index]. Arrays are modified in
+// place and can only be assigned within their bounds.
+func evalIndexAssignment(left, index, val object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		elements := left.(*object.Array).Elements
+		idx := index.(*object.Integer).Value
+		if idx < 0 || idx >= int64(len(elements)) {
+			return newError("index out of range: %d (length %d)", idx, len(elements))
+		}
+		elements[idx] = val
+		return val
+	case left.Type() == object.HASH_OBJ:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		left.(*object.Hash).Set(key.HashKey(), object.HashPair{Key: index, Value: val})
+		return val
+	default:
+		return newError("index assignment not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+// ============================
+// FUNCTION APPLICATION
+// ============================
+
+// evalCallExpression evaluates a call. piped is the value fed into the call
+// by |>, which goes before the other arguments, or nil.
+func evalCallExpression(node *ast.CallExpression, piped object.Object, env *object.Environment) object.Object {
+	function := Eval(node.Function, env)
+	if isSignal(function) {
+		return function
+	}
+	args, keywords, err := evalCallArguments(node.Arguments, env)
+	if err != nil {
+		return err
+	}
+	if piped != nil {
+		args = append([]object.Object{piped}, args...)
+	}
+	return traceCall(applyFunction(function, args, keywords), function, node)
+}
+
+// evalCallArguments evaluates the arguments of a call from left to right,
+// keeping keyword arguments apart from positional ones.
+func evalCallArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, map[string]object.Object, object.Object) {
+	args := []object.Object{}
+	keywords := map[string]object.Object{}
+	for _, e := range exps {
+		keyword, isKeyword := e.(*ast.KeywordArgument)
+		if isKeyword {
+			e = keyword.Value
+		}
+		evaluated := Eval(e, env)
+		if isSignal(evaluated) {
+			return nil, nil, evaluated
+		}
+		if isKeyword {
+			keywords[keyword.Name.Value] = evaluated
+		} else {
+			args = append(args, evaluated)
+		}
+	}
+	return args, keywords, nil
+}
+
+// applyFunction calls fn with positional args and keyword arguments. User
+// functions run in a new environment enclosing the one they were defined in.
+func applyFunction(fn object.Object, args []object.Object, keywords map[string]object.Object) object.Object {
+	switch function := fn.(type) {
+	case *object.Function:
+		extendedEnv, err := extendFunctionEnv(function, args, keywords)
+		if err != nil {
+			return unwrapReturnValue(err)
+		}
+		evaluated := Eval(function.Body, extendedEnv)
+		return unwrapReturnValue(evaluated)
+	case *object.Builtin:
+		if len(keywords) > 0 {
+			return newError("builtin functions do not take keyword arguments")
+		}
+		return function.Fn(args...)
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
+}
+
+// extendFunctionEnv binds the parameters of fn. Positional arguments fill
+// the parameters in order, keyword arguments the parameters with their name,
+// and a rest parameter gets an array of the positional arguments left over.
+// Parameters that got no argument are set to their default, evaluated in
+// the new environment so that it can refer to the parameters before it.
+func extendFunctionEnv(fn *object.Function, args []object.Object, keywords map[string]object.Object) (*object.Environment, object.Object) {
+	if err := checkArity(fn, args, keywords); err != nil {
+		return nil, err
+	}
+	env := object.NewEnclosedEnvironment(fn.Env)
+	for i, param := range fn.Parameters {
+		name := param.Name.Value
+		value, isKeyword := keywords[name]
+		switch {
+		case param.Rest:
+			rest := []object.Object{}
+			if i < len(args) {
+				rest = append(rest, args[i:]...)
+			}
+			value = &object.Array{Elements: rest}
+		case i < len(args):
+			if isKeyword {
+				return nil, newError("argument %s of %s given more than once", name, functionName(fn))
+			}
+			value = args[i]
+		case isKeyword:
+		case param.Default != nil:
+			value = Eval(param.Default, env)
+			if isSignal(value) {
+				return nil, value
+			}
+		default:
+			return nil, newError("missing argument %s of %s", name, functionName(fn))
+		}
+		env.Set(name, value)
+	}
+	return env, nil
+}
+
+// checkArity reports calls to fn with too many or too few positional
+// arguments, or with keyword arguments that name no parameter.
+func checkArity(fn *object.Function, args []object.Object, keywords map[string]object.Object) object.Object {
+	required, limit := 0, len(fn.Parameters)
+	named := map[string]bool{}
+	for _, param := range fn.Parameters {
+		switch {
+		case param.Rest:
+			limit = -1
+		case param.Default == nil:
+			required++
+		}
+		named[param.Name.Value] = !param.Rest
+	}
+	for name := range keywords {
+		if !named[name] {
+			return newError("%s has no parameter named %s", functionName(fn), name)
+		}
+	}
+	tooMany := limit >= 0 && len(args) > limit
+	tooFew := len(keywords) == 0 && len(args) < required
+	if !tooMany && !tooFew {
+		return nil
+	}
+	want := strconv.Itoa(required)
+	switch {
+	case limit < 0:
+		want = "at least " + want
+	case limit > required:
+		want += " to " + strconv.Itoa(limit)
+	}
+	return newError("wrong number of arguments to %s: want=%s, got=%d", functionName(fn), want, len(args))
+}
+
+// evalMethodCallExpression evaluates object.name(args). A function stored
+// under "name" in a hash is called with args alone. Any other name is looked
+// up like an identifier and called as name(object, args), so xs.len() is
+// len(xs). piped is the value fed into the call by |>, which goes before
+// args, or nil.
+func evalMethodCallExpression(node *ast.MethodCallExpression, piped object.Object, env *object.Environment) object.Object {
+	receiver := Eval(node.Object, env)
+	if isSignal(receiver) {
+		return receiver
+	}
+	args, keywords, err := evalCallArguments(node.Arguments, env)
+	if err != nil {
+		return err
+	}
+	if piped != nil {
+		args = append([]object.Object{piped}, args...)
+	}
+	name := node.Method.Value
+	if hash, ok := receiver.(*object.Hash); ok {
+		if pair, ok := hash.Get((&object.String{Value: name}).HashKey()); ok {
+			return traceCall(applyFunction(pair.Value, args, keywords), pair.Value, node)
+		}
+	}
+	function, ok := env.Get(name)
+	if !ok {
+		function, ok = builtins[name]
+	}
+	if !ok {
+		return newError("%s has no method %s", receiver.Type(), name)
+	}
+	args = append([]object.Object{receiver}, args...)
+	return traceCall(applyFunction(function, args, keywords), function, node)
+}
+
+// traceCall adds the call of fn at node to the stack trace of result when
+// result is an error that came out of a user function.
+func traceCall(result, fn object.Object, node ast.Node) object.Object {
+	err, isErr := result.(*object.Error)
+	function, isFunction := fn.(*object.Function)
+	if isErr && isFunction {
+		err.Trace = append(err.Trace, fmt.Sprintf("%s called at %s", functionName(function), node.Pos()))
+	}
+	return result
+}
+
+// functionName names fn in error messages.
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "anonymous function"
+	}
+	return fn.Name
+}
+
+// unwrapReturnValue stops a return value from propagating past the function it returned from.
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+	return obj
+}
+
+// ============================
+// HELPERS
+// ============================
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+// isTruthy reports whether obj counts as true in a condition; only null and false do not.
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL:
+		return false
+	case TRUE:
+		return true
+	case FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+// isNumber reports whether obj is an integer or a float.
+func isNumber(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.BigInteger, *object.Float:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a number to float64.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ
+	}
+	return false
+}
+
+// isSignal reports whether obj stops the evaluation of the surrounding
+// code: an error, a return value, break or continue. These are passed on
+// unchanged wherever an expression would use them as a value.
+func isSignal(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+	switch obj.Type() {
+	case object.ERROR_OBJ, object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	}
+	return false
+}
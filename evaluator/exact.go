@@ -0,0 +1,209 @@
+package evaluator
+
+import (
+	"math/big"
+
+	"github.com/TusharAbhinav/monkey/object"
+)
+
+// ============================
+// EXACT RATIONALS AND DECIMALS
+// ============================
+
+// Rationals and decimals never round. Mixing them follows these rules:
+//
+//	integer  op rational -> rational
+//	integer  op decimal  -> decimal
+//	decimal  op rational -> rational
+//	decimal  /  decimal  -> decimal when the quotient has a finite decimal
+//	                        expansion, rational otherwise (1.00d / 3 is 1/3r)
+//	decimal  ** integer  -> decimal, under the same condition
+//	rational ** integer  -> rational
+//
+// Mixing them with floats is a type mismatch, since the float would lose
+// the precision the exact value was chosen for.
+
+// evalExactInfixExpression evaluates arithmetic where at least one side is
+// a rational or a decimal and the other side is exact as well.
+func evalExactInfixExpression(operator string, left, right object.Object) object.Object {
+	_, leftRational := left.(*object.Rational)
+	_, rightRational := right.(*object.Rational)
+	if leftRational || rightRational {
+		return evalRationalInfixExpression(operator, left, right)
+	}
+	return evalDecimalInfixExpression(operator, left, right)
+}
+
+func evalRationalInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toRat(left)
+	rightVal := toRat(right)
+	switch operator {
+	case "+":
+		return &object.Rational{Value: new(big.Rat).Add(leftVal, rightVal)}
+	case "-":
+		return &object.Rational{Value: new(big.Rat).Sub(leftVal, rightVal)}
+	case "*":
+		return &object.Rational{Value: new(big.Rat).Mul(leftVal, rightVal)}
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return &object.Rational{Value: new(big.Rat).Quo(leftVal, rightVal)}
+	case "**":
+		return evalExactPower(left, right)
+	default:
+		return evalExactComparison(operator, left, right)
+	}
+}
+
+func evalDecimalInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toDecimal(left)
+	rightVal := toDecimal(right)
+	scale := max(leftVal.Scale, rightVal.Scale)
+	switch operator {
+	case "+":
+		sum := new(big.Int).Add(leftVal.Rescaled(scale), rightVal.Rescaled(scale))
+		return &object.Decimal{Unscaled: sum, Scale: scale}
+	case "-":
+		difference := new(big.Int).Sub(leftVal.Rescaled(scale), rightVal.Rescaled(scale))
+		return &object.Decimal{Unscaled: difference, Scale: scale}
+	case "*":
+		product := new(big.Int).Mul(leftVal.Unscaled, rightVal.Unscaled)
+		return &object.Decimal{Unscaled: product, Scale: leftVal.Scale + rightVal.Scale}
+	case "/":
+		if rightVal.Unscaled.Sign() == 0 {
+			return newError("division by zero")
+		}
+		quotient := new(big.Rat).Quo(leftVal.Rat(), rightVal.Rat())
+		if decimal, ok := decimalFromRat(quotient, scale); ok {
+			return decimal
+		}
+		return &object.Rational{Value: quotient}
+	case "**":
+		return evalExactPower(left, right)
+	default:
+		return evalExactComparison(operator, left, right)
+	}
+}
+
+func evalExactComparison(operator string, left, right object.Object) object.Object {
+	cmp := toRat(left).Cmp(toRat(right))
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(cmp < 0)
+	case ">":
+		return nativeBoolToBooleanObject(cmp > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(cmp <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(cmp >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(cmp == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(cmp != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalExactPower raises a rational or a decimal to an integer power. Powers
+// of decimals stay decimals as long as the result has a finite expansion.
+func evalExactPower(left, right object.Object) object.Object {
+	exponent, ok := right.(*object.Integer)
+	if !ok {
+		return newError("unknown operator: %s ** %s", left.Type(), right.Type())
+	}
+	n := exponent.Value
+	if decimal, ok := left.(*object.Decimal); ok && n >= 0 {
+		if err := checkPowerSize(decimal.Unscaled, big.NewInt(n)); err != nil {
+			return err
+		}
+		if int64(decimal.Scale)*n > maxPowerBits {
+			// 0.1d ** n has n digits after the point
+			return newError("exponent too large: %d", n)
+		}
+		power := new(big.Int).Exp(decimal.Unscaled, big.NewInt(n), nil)
+		return &object.Decimal{Unscaled: power, Scale: decimal.Scale * int(n)}
+	}
+
+	base := toRat(left)
+	if n < 0 {
+		if base.Sign() == 0 {
+			return newError("division by zero")
+		}
+		base = new(big.Rat).Inv(base)
+		n = -n
+	}
+	e := big.NewInt(n)
+	if err := checkPowerSize(base.Num(), e); err != nil {
+		return err
+	}
+	if err := checkPowerSize(base.Denom(), e); err != nil {
+		return err
+	}
+	power := new(big.Rat).SetFrac(
+		new(big.Int).Exp(base.Num(), e, nil),
+		new(big.Int).Exp(base.Denom(), e, nil))
+	if decimal, ok := left.(*object.Decimal); ok {
+		if result, ok := decimalFromRat(power, decimal.Scale); ok {
+			return result
+		}
+	}
+	return &object.Rational{Value: power}
+}
+
+// decimalFromRat converts r to a decimal with at least minScale digits
+// after the point. It fails when r has no finite decimal expansion, which
+// is the case when its denominator has prime factors other than 2 and 5.
+func decimalFromRat(r *big.Rat, minScale int) (*object.Decimal, bool) {
+	denominator := new(big.Int).Set(r.Denom())
+	remainder := new(big.Int)
+	for _, factor := range []*big.Int{big.NewInt(2), big.NewInt(5)} {
+		for {
+			quotient, rem := new(big.Int).QuoRem(denominator, factor, remainder)
+			if rem.Sign() != 0 {
+				break
+			}
+			denominator = quotient
+		}
+	}
+	if !denominator.IsInt64() || denominator.Int64() != 1 {
+		return nil, false
+	}
+
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	scale := 0
+	for ; scale < minScale || !scaled.IsInt(); scale++ {
+		scaled.Mul(scaled, ten)
+	}
+	return &object.Decimal{Unscaled: new(big.Int).Set(scaled.Num()), Scale: scale}, true
+}
+
+// isExact reports whether obj is an integer, a rational or a decimal.
+func isExact(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Rational, *object.Decimal:
+		return true
+	}
+	return isInteger(obj)
+}
+
+// toRat converts an exact number to a big.Rat.
+func toRat(obj object.Object) *big.Rat {
+	switch obj := obj.(type) {
+	case *object.Rational:
+		return obj.Value
+	case *object.Decimal:
+		return obj.Rat()
+	}
+	return new(big.Rat).SetInt(toBigInt(obj))
+}
+
+// toDecimal converts an integer or a decimal to a decimal.
+func toDecimal(obj object.Object) *object.Decimal {
+	if decimal, ok := obj.(*object.Decimal); ok {
+		return decimal
+	}
+	return &object.Decimal{Unscaled: toBigInt(obj), Scale: 0}
+}
@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/TusharAbhinav/monkey/object"
+)
+
+// ============================
+// ARBITRARY-PRECISION INTEGERS
+// ============================
+
+// Integers are int64 values until a literal or a result does not fit, at
+// which point they are promoted to object.BigInteger. Results that fit in
+// an int64 again are demoted, so every integer value has exactly one
+// representation and == can compare them as numbers.
+
+// maxPowerBits bounds the size of the results of ** and <<, which would
+// otherwise let 2 ** 100000000000 or 1 << 4294967295 use up all memory.
+const maxPowerBits = 1 << 22
+
+// checkedIntegerOp applies +, - or * to two int64 values and reports
+// whether the result fit in an int64.
+func checkedIntegerOp(operator string, a, b int64) (int64, bool) {
+	switch operator {
+	case "+":
+		result := a + b
+		return result, (result > a) == (b > 0)
+	case "-":
+		result := a - b
+		return result, (result < a) == (b > 0)
+	case "*":
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		result := a * b
+		if result/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+			return 0, false
+		}
+		return result, true
+	}
+	return 0, false
+}
+
+// evalBigIntegerInfixExpression evaluates integer arithmetic and comparisons
+// with arbitrary precision.
+func evalBigIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+	switch operator {
+	case "+":
+		return integerObject(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return integerObject(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return integerObject(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		// Quo truncates towards zero like int64 division does
+		return integerObject(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		// and Rem gives the remainder of that division, like int64 % does
+		return integerObject(new(big.Int).Rem(leftVal, rightVal))
+	case "**":
+		if rightVal.Sign() < 0 {
+			// 2 ** -1 is not an integer
+			return &object.Float{Value: math.Pow(toFloat(left), toFloat(right))}
+		}
+		if err := checkPowerSize(leftVal, rightVal); err != nil {
+			return err
+		}
+		return integerObject(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "&":
+		return integerObject(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return integerObject(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return integerObject(new(big.Int).Xor(leftVal, rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if rightVal.BitLen() > 32 {
+			return newError("shift count too large: %s", rightVal)
+		}
+		if operator == "<<" {
+			if leftVal.Sign() != 0 && uint64(leftVal.BitLen())+rightVal.Uint64() > maxPowerBits {
+				return newError("shift count too large: %s", rightVal)
+			}
+			return integerObject(new(big.Int).Lsh(leftVal, uint(rightVal.Uint64())))
+		}
+		// Rsh keeps the sign, so -8 >> 1 is -4 as with int64
+		return integerObject(new(big.Int).Rsh(leftVal, uint(rightVal.Uint64())))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// checkPowerSize returns an error when base ** exponent would have more
+// than maxPowerBits bits. Powers of 0, 1 and -1 never grow.
+func checkPowerSize(base, exponent *big.Int) *object.Error {
+	if base.CmpAbs(big.NewInt(1)) <= 0 {
+		return nil
+	}
+	// a base of n bits has a power of at least (n-1)*exponent+1 bits
+	bits := new(big.Int).Mul(big.NewInt(int64(base.BitLen()-1)), exponent)
+	if bits.Cmp(big.NewInt(maxPowerBits)) >= 0 {
+		return newError("exponent too large: %s", exponent)
+	}
+	return nil
+}
+
+// integerObject returns value as an Integer when it fits in an int64 and as
+// a BigInteger otherwise.
+func integerObject(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+// isInteger reports whether obj is an Integer or a BigInteger.
+func isInteger(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.BigInteger:
+		return true
+	}
+	return false
+}
+
+// toBigInt converts an Integer or BigInteger to a big.Int. The result must
+// not be modified, as it may be shared with the object.
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	}
+	return new(big.Int)
+}
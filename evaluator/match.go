@@ -0,0 +1,186 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/TusharAbhinav/monkey/ast"
+	"github.com/TusharAbhinav/monkey/object"
+)
+
+// ============================
+// MATCH EXPRESSIONS
+// ============================
+
+// evalMatchExpression evaluates the body of the first arm whose pattern
+// matches the subject and whose guard, if any, is truthy. Each arm binds its
+// names in a scope of its own, which the guard and the body run in.
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isSignal(subject) {
+		return subject
+	}
+	for _, arm := range node.Arms {
+		armEnv := object.NewEnclosedEnvironment(env)
+		matched, err := matchPattern(arm.Pattern, subject, armEnv)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isSignal(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+		return Eval(arm.Body, armEnv)
+	}
+	return newError("no match arm matches %s", subject.Inspect())
+}
+
+// ============================
+// PATTERNS
+// ============================
+
+// bindPattern binds the names in the pattern of a let statement, or returns
+// an error describing why val does not have the shape of pattern.
+func bindPattern(pattern ast.Pattern, val object.Object, env *object.Environment) object.Object {
+	matched, err := matchPattern(pattern, val, env)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return newError("%s", patternMismatch(pattern, val, env))
+	}
+	return nil
+}
+
+// matchPattern reports whether value has the shape of pattern, binding the
+// names in pattern to the matching parts of value in env. A non-nil error is
+// returned when a literal in the pattern cannot be evaluated.
+func matchPattern(pattern ast.Pattern, value object.Object, env *object.Environment) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+	case *ast.BindingPattern:
+		env.Set(pattern.Name.Value, value)
+		return true, nil
+	case *ast.LiteralPattern:
+		literal := Eval(pattern.Value, env)
+		if isError(literal) {
+			return false, literal
+		}
+		// values that cannot be compared, like a string and a number, do not match
+		return evalInfixExpression("==", value, literal) == TRUE, nil
+	case *ast.ArrayPattern:
+		return matchArrayPattern(pattern, value, env)
+	case *ast.HashPattern:
+		return matchHashPattern(pattern, value, env)
+	}
+	return false, nil
+}
+
+// matchArrayPattern matches arrays with as many elements as the pattern, or
+// at least as many when the pattern has a rest binding. The rest binding
+// gets a new array holding the remaining elements.
+func matchArrayPattern(pattern *ast.ArrayPattern, value object.Object, env *object.Environment) (bool, object.Object) {
+	array, ok := value.(*object.Array)
+	if !ok {
+		return false, nil
+	}
+	if len(array.Elements) < len(pattern.Elements) ||
+		pattern.Rest == nil && len(array.Elements) != len(pattern.Elements) {
+		return false, nil
+	}
+	for i, element := range pattern.Elements {
+		if matched, err := matchPattern(element, array.Elements[i], env); !matched || err != nil {
+			return false, err
+		}
+	}
+	if pattern.Rest != nil && pattern.Rest.Value != "_" {
+		rest := make([]object.Object, len(array.Elements)-len(pattern.Elements))
+		copy(rest, array.Elements[len(pattern.Elements):])
+		env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
+	}
+	return true, nil
+}
+
+// matchHashPattern matches hashes that have every key of the pattern with a
+// value matching the pattern for that key.
+func matchHashPattern(pattern *ast.HashPattern, value object.Object, env *object.Environment) (bool, object.Object) {
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return false, nil
+	}
+	for _, pair := range pattern.Pairs {
+		key := evalPatternKey(pair.Key, env)
+		if isError(key) {
+			return false, key
+		}
+		// the parser only accepts keys that are hashable
+		entry, ok := hash.Get(key.(object.Hashable).HashKey())
+		if !ok {
+			return false, nil
+		}
+		if matched, err := matchPattern(pair.Value, entry.Value, env); !matched || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// evalPatternKey evaluates a key of a hash pattern. A name stands for the
+// string key of the same name.
+func evalPatternKey(key ast.Expression, env *object.Environment) object.Object {
+	if name, ok := key.(*ast.Identifier); ok {
+		return &object.String{Value: name.Value}
+	}
+	return Eval(key, env)
+}
+
+// patternMismatch explains why value does not match pattern, naming the
+// first part of pattern it fails on.
+func patternMismatch(pattern ast.Pattern, value object.Object, env *object.Environment) string {
+	switch pattern := pattern.(type) {
+	case *ast.LiteralPattern:
+		return fmt.Sprintf("expected %s, got %s", pattern, value.Inspect())
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+		if !ok {
+			return fmt.Sprintf("cannot destructure %s with an array pattern", value.Type())
+		}
+		switch {
+		case pattern.Rest != nil && len(array.Elements) < len(pattern.Elements):
+			return fmt.Sprintf("expected an array of at least %d elements, got %d",
+				len(pattern.Elements), len(array.Elements))
+		case pattern.Rest == nil && len(array.Elements) != len(pattern.Elements):
+			return fmt.Sprintf("expected an array of %d elements, got %d",
+				len(pattern.Elements), len(array.Elements))
+		}
+		for i, element := range pattern.Elements {
+			if matched, _ := matchPattern(element, array.Elements[i], env); !matched {
+				return patternMismatch(element, array.Elements[i], env)
+			}
+		}
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return fmt.Sprintf("cannot destructure %s with a hash pattern", value.Type())
+		}
+		for _, pair := range pattern.Pairs {
+			key := evalPatternKey(pair.Key, env)
+			entry, ok := hash.Get(key.(object.Hashable).HashKey())
+			if !ok {
+				return fmt.Sprintf("hash has no key %s", pair.Key)
+			}
+			if matched, _ := matchPattern(pair.Value, entry.Value, env); !matched {
+				return patternMismatch(pair.Value, entry.Value, env)
+			}
+		}
+	}
+	return fmt.Sprintf("%s does not match %s", value.Inspect(), pattern)
+}
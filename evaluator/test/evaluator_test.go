@@ -0,0 +1,1126 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/TusharAbhinav/monkey/evaluator"
+	lexer "github.com/TusharAbhinav/monkey/lexer"
+	"github.com/TusharAbhinav/monkey/object"
+	parser "github.com/TusharAbhinav/monkey/parser"
+)
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"-10", -10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"2 * (5 + 10)", 30},
+		{"3 * 3 * 3 + 10", 37},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"let min = -9223372036854775807 - 1; -min", "9223372036854775808"},
+		{"let min = -9223372036854775807 - 1; min / -1", "9223372036854775808"},
+		{"123456789012345678901234567890 * 10", "1234567890123456789012345678900"},
+		{"-123456789012345678901234567890 / 7", "-17636684144620811271604938270"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		result, ok := evaluated.(*object.BigInteger)
+		if !ok {
+			t.Errorf("%q: object is not BigInteger. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%q: wrong value. expected=%s, got=%s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestBigIntegersDemoteWhenTheyFit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"9223372036854775808 - 1", 9223372036854775807},
+		{"(9223372036854775807 + 10) - 20", 9223372036854775797},
+		{"18446744073709551616 / 4294967296", 4294967296},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBigIntegerComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"9223372036854775808 > 1", true},
+		{"1 < 9223372036854775808", true},
+		{"9223372036854775808 == 9223372036854775807 + 1", true},
+		{"9223372036854775808 != 9223372036854775808", false},
+		{"9223372036854775808 == 9223372036854775808.0", true},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestExactNumberArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1/3r", "1/3r"},
+		{"1/3r + 1/6r", "1/2r"},
+		{"1/3r * 3", "1r"},
+		{"-(2/4r)", "-1/2r"},
+		{"12.50d", "12.50d"},
+		{"12.50d + 0.5d", "13.00d"},
+		{"0.1d + 0.2d", "0.3d"},
+		{"19.99d * 3", "59.97d"},
+		{"1.5d * 1.5d", "2.25d"},
+		{"10.00d - 20", "-10.00d"},
+		{"-0.05d", "-0.05d"},
+		{"1.00d / 4", "0.25d"},
+		{"1d / 8", "0.125d"},
+		{"10.00d / 3", "10/3r"},
+		{"0.5d + 1/3r", "5/6r"},
+		{"100000000000000000000.01d * 2", "200000000000000000000.02d"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: wrong result. expected=%s, got=%s (%T)", tt.input, tt.expected, evaluated.Inspect(), evaluated)
+		}
+	}
+}
+
+func TestExactNumberComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"0.1d + 0.2d == 0.3d", true},
+		{"1.50d == 1.5d", true},
+		{"2.00d == 2", true},
+		{"1/2r == 0.5d", true},
+		{"1/3r < 0.34d", true},
+		{"2r != 2", false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"-8 >> 1", -4},
+		{"1 + 2 * 3 % 4", 3},
+		{"(2 ** 70) >> 60", 1024},
+		{"(2 ** 70) & 0xFF", 0},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestPowerResults(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 ** 64", "18446744073709551616"},
+		{"1 << 64", "18446744073709551616"},
+		{"~(2 ** 64)", "-18446744073709551617"},
+		{"2 ** -1", "0.5"},
+		{"2.0 ** 0.5 > 1.41", "true"},
+		{"7.5 % 2", "1.5"},
+		{"(2/3r) ** 2", "4/9r"},
+		{"(2/3r) ** -2", "9/4r"},
+		{"1.5d ** 2", "2.25d"},
+		{"2.0d ** -1", "0.5d"},
+		{"3.0d ** -1", "1/3r"},
+		{"2 ** 100000000000", "ERROR: exponent too large: 100000000000"},
+		{"2 ** 4194304", "ERROR: exponent too large: 4194304"},
+		{"(-1) ** 100000000001", "-1"},
+		{"1 ** (2 ** 70)", "1"},
+		{"0 ** 100000000000", "0"},
+		{"(1/2r) ** 100000000000", "ERROR: exponent too large: 100000000000"},
+		{"1.5d ** 100000000000", "ERROR: exponent too large: 100000000000"},
+		{"0.1d ** 100000000000", "ERROR: exponent too large: 100000000000"},
+		{"1 << 4294967295", "ERROR: shift count too large: 4294967295"},
+		{"1 << 4194304", "ERROR: shift count too large: 4194304"},
+		{"(1 << 4194303) > 0", "true"},
+		{"0 << 4294967295", "0"},
+		{"-1 >> 4294967295", "-1"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: wrong result. expected=%s, got=%s (%T)", tt.input, tt.expected, evaluated.Inspect(), evaluated)
+		}
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5", 2.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"10 - 2.5 * 2", 5},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestMixedNumberComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 == 1.0", true},
+		{"1.0 != 1", false},
+		{"0.5 < 1", true},
+		{"2 > 2.5", false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 + 1.5", "3.0"},
+		{"0.1", "0.1"},
+		{"1e21", "1e+21"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: Inspect wrong. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"1 == 2", false},
+		{"true == true", true},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == true", false},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 2", false},
+		{"2.5 >= 2", true},
+		{"9223372036854775808 >= 9223372036854775808", true},
+		{"1/3r <= 0.33d", false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 && 0", true},
+		{"false || 5", true},
+		// the right side is not evaluated once the left side decides
+		{"false && missing", false},
+		{"true || missing", true},
+		{"let f = fn() { 1 / 0 }; false && f()", false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBangOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!!false", false},
+		{"!!5", true},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{`
+if (10 > 1) {
+  if (10 > 1) {
+    return 10;
+  }
+  return 1;
+}`, 10},
+		{"let f = fn() { let x = if (true) { return 1; } else { 2 }; 3 }; f()", 1},
+		{"let f = fn() { let xs = [if (true) { return 1; } else { 2 }]; 3 }; f()", 1},
+		{"let f = fn() { 10 + if (true) { return 1; } else { 2 } }; f()", 1},
+		{"let f = fn() { let g = fn(x) { x }; g(if (true) { return 1; } else { 2 }); 3 }; f()", 1},
+		{"let f = fn() { return if (true) { return 1; } else { 2 }; }; f()", 1},
+		{"let f = fn() { let h = {\"a\": if (true) { return 1; } else { 2 }}; 3 }; f()", 1},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{`
+if (10 > 1) {
+  if (10 > 1) {
+    return true + false;
+  }
+  return 1;
+}`, "unknown operator: BOOLEAN + BOOLEAN"},
+		{"foobar", "identifier not found: foobar"},
+		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
+		{`"a" + 1`, "type mismatch: STRING + INTEGER"},
+		{"10 / 0", "division by zero"},
+		{"1.5 / 0", "division by zero"},
+		{"5 % 0", "division by zero"},
+		{"y = 1", "assignment to undeclared identifier: y"},
+		{"while (missing) { }", "identifier not found: missing"},
+		{"for (x in 5) { }", "cannot iterate over INTEGER"},
+		{`1.."a"`, "range bounds must be INTEGER, got INTEGER..STRING"},
+		{`[1][true:]`, "slice bounds must be INTEGER, got BOOLEAN"},
+		{`{1: 2}[0:1]`, "slice operator not supported: HASH"},
+		{`range("a")`, "arguments to `range` must be INTEGER, got STRING"},
+		{"let i = 0; while (true) { i += 1; if (i > 3) { i + true } }", "type mismatch: INTEGER + BOOLEAN"},
+		{"y += 1", "identifier not found: y"},
+		{"let xs = [1]; xs[1] = 2", "index out of range: 1 (length 1)"},
+		{`let s = "ab"; s[0] = "c"`, "index assignment not supported: STRING[INTEGER]"},
+		{`let x = "a"; x -= 1`, "type mismatch: STRING - INTEGER"},
+		{"1 << -1", "negative shift count: -1"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{"(1/2r) ** (1/2r)", "unknown operator: RATIONAL ** RATIONAL"},
+		{"true && missing", "identifier not found: missing"},
+		{`"a" <= "b"`, "unknown operator: STRING <= STRING"},
+		{"1r / 0", "division by zero"},
+		{"1.5d / 0.0d", "division by zero"},
+		{"1.5d + 1.5", "type mismatch: DECIMAL + FLOAT"},
+		{"0.5 * 1/3r", "type mismatch: FLOAT / RATIONAL"},
+		{"-true + 1.5", "unknown operator: -BOOLEAN"},
+		{"let f = fn(x) { x }; f(1, 2)", "wrong number of arguments to f: want=1, got=2"},
+		{"5(1)", "not a function: INTEGER"},
+		{`"abc"[0]`, "index operator not supported: STRING[INTEGER]"},
+		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{`{[1]: 2}`, "unusable as hash key: ARRAY"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", "12"},
+		{"let [first, ...rest] = [1, 2, 3]; rest", "[2, 3]"},
+		{"let [x, ...rest] = [1]; rest", "[]"},
+		{"let [[a, b], _, c] = [[1, 2], 3, 4]; [a, b, c]", "[1, 2, 4]"},
+		{`let person = {"name": "Ann", "age": 40}; let {name, age: years} = person; [name, years]`, "[Ann, 40]"},
+		{`let {"xy": [x, y], 1: z} = {"xy": [3, 4], 1: 5}; x + y + z`, "12"},
+		{"let [a, b] = [1];", "ERROR: expected an array of 2 elements, got 1"},
+		{"let [a, b] = [1, 2, 3];", "ERROR: expected an array of 2 elements, got 3"},
+		{"let [a, b, ...c] = [1];", "ERROR: expected an array of at least 2 elements, got 1"},
+		{"let [a, [b, c]] = [1, 2];", "ERROR: cannot destructure INTEGER with an array pattern"},
+		{`let {name} = {"age": 1};`, "ERROR: hash has no key name"},
+		{"let {name} = [1];", "ERROR: cannot destructure ARRAY with a hash pattern"},
+	}
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 1; x = x + 1", 2},
+		{"let x = 10; x += 5; x", 15},
+		{"let x = 10; x -= 5; x", 5},
+		{"let x = 10; x *= 5; x", 50},
+		{"let x = 10; x /= 5; x", 2},
+		{"let x = 10; x %= 4; x", 2},
+		{"let a = 0; let b = 0; a = b = 3; a + b", 6},
+		{"let x = 1; let f = fn() { x = 5 }; f(); x", 5},
+		{"let x = 1; let f = fn(x) { x = 5 }; f(0); x", 1},
+		{"let xs = [1, 2, 3]; xs[1] = 20; xs[1]", 20},
+		{"let xs = [1, 2, 3]; xs[2] += 10; xs[2]", 13},
+		{"let xs = [1, 2]; let ys = xs; ys[0] = 9; xs[0]", 9},
+		{`let h = {"a": 1}; h["a"] += 1; h["b"] = 5; h["a"] + h["b"]`, 7},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let i = 0; while (i < 100000) { i += 1 }; i", 100000},
+		{"let sum = 0; for (let i = 1; i <= 10; i += 1) { sum += i }; sum", 55},
+		{"let i = 0; while (true) { i += 1; if (i == 7) { break; } }; i", 7},
+		{"let sum = 0; for (let i = 0; i < 10; i += 1) { if (i % 2 == 0) { continue; } sum += i }; sum", 25},
+		{"let n = 0; for (let i = 0; i < 3; i += 1) { for (let j = 0; j < 10; j += 1) { if (j == 2) { break; } n += 1 } }; n", 6},
+		{"let f = fn() { for (let i = 0; ; i += 1) { if (i == 4) { return i * 10; } } }; f()", 40},
+		{"let i = 100; for (let i = 0; i < 3; i += 1) { }; i", 100},
+		{"let i = 0; for (; i < 3; ) { i += 1 }; i", 3},
+		{"let n = 0; while (n < 3) { n += 1; let x = if (n == 1) { break; } else { 0 }; }; n", 1},
+		{"let n = 0; let s = 0; while (n < 5) { n += 1; s += n + if (n % 2 == 0) { continue; } else { 0 } }; s", 9},
+		{"let n = 0; let xs = []; while (n < 3) { n += 1; xs = push(xs, if (n == 2) { break; } else { n }) }; len(xs)", 1},
+		{"let n = 0; while (n < 3) { n += 1; let xs = [if (n == 1) { break; } else { 0 }]; }; n", 1},
+		{"let n = 0; for (x in 0..5) { n += {\"k\": if (x > 1) { continue; } else { 1 }}[\"k\"] }; n", 2},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestForInLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let out = []; for (x in [1, 2, 3]) { out = push(out, x * 2) }; out", "[2, 4, 6]"},
+		{"let out = []; for (i, x in [7, 8]) { out = push(out, i * 10 + x) }; out", "[7, 18]"},
+		{`let out = []; for (k in {"b": 1, "a": 2}) { out = push(out, k) }; out`, "[b, a]"},
+		{`let out = []; for (k, v in {"b": 1, "a": 2}) { out = push(out, k + "=" + v) }; out`, "ERROR: type mismatch: STRING + INTEGER"},
+		{`let out = []; for (k, v in {"b": "1", "a": "2"}) { out = push(out, k + "=" + v) }; out`, "[b=1, a=2]"},
+		{`let out = []; for (i, c in "añb") { out = push(out, c) }; out`, "[a, ñ, b]"},
+		{"let sum = 0; for (i in range(5)) { sum += i }; sum", "10"},
+		{"let sum = 0; for (i in range(2, 5)) { sum += i }; sum", "9"},
+		{"let n = 0; for (i in range(1000000000000)) { if (i == 3) { break; } n += 1 }; n", "3"},
+		{"let fs = []; for (x in [1, 2]) { fs = push(fs, fn() { x }) }; fs[0]() + fs[1]() * 10", "21"},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x } } }; f()", "2"},
+		{"len(range(3, 10))", "7"},
+		{"len(range(10, 3))", "0"},
+	}
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRangesAndSlices(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0..5", "0..5"},
+		{"1..=3", "1..=3"},
+		{"len(0..1000000000)", "1000000000"},
+		{"len(1..=3)", "3"},
+		{"len(5..1)", "0"},
+		{"len(0..=9223372036854775807)", "9223372036854775808"},
+		{"len(-9223372036854775808..9223372036854775807)", "18446744073709551615"},
+		{"len(-9223372036854775808..=9223372036854775807)", "18446744073709551616"},
+		{"len(9223372036854775807..-9223372036854775808)", "0"},
+		{"let sum = 0; for (i in 1..=100) { sum += i }; sum", "5050"},
+		{"let out = []; for (i, x in 10..13) { out = push(out, i * 100 + x) }; out", "[10, 111, 212]"},
+		{"let n = 0; for (i in 9223372036854775806..=9223372036854775807) { n += 1 }; n", "2"},
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:-1]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3][:]", "[1, 2, 3]"},
+		{"[1, 2, 3][2:1]", "[]"},
+		{"[1, 2, 3][-10:10]", "[1, 2, 3]"},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[:-1]`, "hell"},
+		{`"añb"[1:2]`, "ñ"},
+		{`len("héllo")`, "5"},
+		{`let s = "héllo"; s[len(s)-1:]`, "o"},
+		{`let s = "日本語"; s[len(s)-2:len(s)]`, "本語"},
+		{`let n = 0; for (c in "héllo") { n += 1 }; n == len("héllo")`, "true"},
+		{"let xs = [1, 2]; let ys = xs[:]; ys[0] = 9; xs[0]", "1"},
+	}
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	describe := `let describe = fn(v) {
+		match (v) {
+			0 => "zero",
+			-1 => "minus one",
+			"hi" => "greeting",
+			[] => "empty",
+			[a, b] => a + b,
+			[first, ...rest] => len(rest),
+			{"k": x} => x,
+			{"tag": "point", "xy": [x, y]} => x * y,
+			n if n > 10 => "big",
+			_ => "other",
+		}
+	};`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"describe(0)", "zero"},
+		{"describe(0.0)", "zero"},
+		{"describe(-1)", "minus one"},
+		{`describe("hi")`, "greeting"},
+		{"describe([])", "empty"},
+		{"describe([1, 2])", "3"},
+		{"describe([1, 2, 3, 4])", "3"},
+		{"describe([1])", "0"},
+		{`describe({"k": 9, "other": 1})`, "9"},
+		{`describe({"tag": "point", "xy": [3, 4]})`, "12"},
+		{`match ({"xy": [3]}) { {"xy": [x, y]} => x, _ => "other" }`, "other"},
+		{"describe(11)", "big"},
+		{"describe(5)", "other"},
+		{"describe(5.5)", "other"},
+		{"let n = 1; match (2) { n => n }; n", "1"},
+		{"match (true) { true => { let x = 1; x + 1 } false => 0 }", "2"},
+		{"let f = fn(xs) { for (x in xs) { match (x) { 2 => { return x * 10 } _ => 0 } } }; f([1, 2, 3])", "20"},
+		{"match (3) { 1 => 1 }", "ERROR: no match arm matches 3"},
+		{`match ([1, 2]) { [a] => a }`, "ERROR: no match arm matches [1, 2]"},
+		{"match (1) { 1 if missing => 1, _ => 2 }", "ERROR: identifier not found: missing"},
+	}
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(describe+tt.input), tt.expected)
+	}
+}
+
+func TestLoopsEvaluateToNull(t *testing.T) {
+	testNullObject(t, testEval("while (false) { 1 }"))
+	testNullObject(t, testEval("for (let i = 0; i < 3; i += 1) { i }"))
+}
+
+func TestFunctionObject(t *testing.T) {
+	input := "fn(x) { x + 2; };"
+	evaluated := testEval(input)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("function has wrong parameters. Parameters=%+v",
+			fn.Parameters)
+	}
+	if fn.Parameters[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	}
+	expectedBody := "(x + 2)"
+	if fn.Body.String() != expectedBody {
+		t.Fatalf("body is not %q. got=%q", expectedBody, fn.Body.String())
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDefaultRestAndKeywordParameters(t *testing.T) {
+	define := "let f = fn(a, b = a * 2, ...rest) { [a, b, rest] }; "
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f(1)", "[1, 2, []]"},
+		{"f(1, 5)", "[1, 5, []]"},
+		{"f(1, 5, 6, 7)", "[1, 5, [6, 7]]"},
+		{"f(1, b: 3)", "[1, 3, []]"},
+		{"f(b: 3, a: 4)", "[4, 3, []]"},
+		{"f(a: 4)", "[4, 8, []]"},
+		{"let n = 10; let g = fn(x = n) { x }; let n = 20; g()", "20"},
+		{"f()", "ERROR: wrong number of arguments to f: want=at least 1, got=0"},
+		{"f(b: 1)", "ERROR: missing argument a of f"},
+		{"f(1, a: 2)", "ERROR: argument a of f given more than once"},
+		{"f(1, c: 2)", "ERROR: f has no parameter named c"},
+		{"f(1, rest: [])", "ERROR: f has no parameter named rest"},
+		{"let g = fn(x, y = 1) { x }; g(1, 2, 3)", "ERROR: wrong number of arguments to g: want=1 to 2, got=3"},
+		{"fn(x) { x }()", "ERROR: wrong number of arguments to anonymous function: want=1, got=0"},
+		{`len("a", b: 1)`, "ERROR: builtin functions do not take keyword arguments"},
+	}
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(define+tt.input), tt.expected)
+	}
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn double(x) { x * 2 } double(21)", "42"},
+		{"let r = twice(3); fn twice(x) { x * 2 } r", "6"},
+		{`fn isEven(n) { if (n == 0) { true } else { isOdd(n - 1) } }
+		  fn isOdd(n) { if (n == 0) { false } else { isEven(n - 1) } }
+		  [isEven(10), isOdd(7), isEven(3)]`, "[true, true, false]"},
+		{`let outer = fn() { fn helper() { later() } fn later() { 7 } helper() }; outer()`, "7"},
+		{"let n = 1; fn get() { n } let n = 2; get()", "2"},
+		{"fn f(x) { x } f", "fn f(x) {\nx\n}"},
+	}
+	for _, tt := range tests {
+		got := testEval(tt.input).Inspect()
+		if got != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `fn inner(x) { x + true }
+fn outer(x) { inner(x) }
+let anon = fn() { outer(1) };
+anon()`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	expected := []string{
+		"inner called at 2:15",
+		"outer called at 3:19",
+		"anon called at 4:1",
+	}
+	if len(errObj.Trace) != len(expected) {
+		t.Fatalf("wrong trace. expected=%q, got=%q", expected, errObj.Trace)
+	}
+	for i, call := range expected {
+		if errObj.Trace[i] != call {
+			t.Errorf("Trace[%d] wrong. expected=%q, got=%q", i, call, errObj.Trace[i])
+		}
+	}
+}
+
+func TestArrowFunctionsAndPipes(t *testing.T) {
+	define := `let map = fn(xs, f) { let out = []; for (x in xs) { out = push(out, f(x)) }; out };
+	let double = x => x * 2;
+	let add = (a, b = 10) => a + b; `
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"double(21)", "42"},
+		{"[add(1), add(1, 2), add(b: 3, a: 4)]", "[11, 3, 7]"},
+		{"(() => 5)()", "5"},
+		{"((x) => { let y = x * 3; y + 1 })(2)", "7"},
+		{"let n = 3; let addN = x => x + n; addN(1)", "4"},
+		{"[1, 2, 3] |> len()", "3"},
+		{"[1, 2, 3] |> len() == 3", "true"},
+		{"5 |> add(1) |> double()", "12"},
+		{"[1, 2, 3] |> map(x => x * x)", "[1, 4, 9]"},
+		{"1 |> add(b: 2)", "3"},
+		{"1 |> add(2, 3)", "ERROR: wrong number of arguments to add: want=1 to 2, got=3\n    in add called at 4:6"},
+	}
+	for _, tt := range tests {
+		got := testEval(define + "\n" + tt.input).Inspect()
+		if got != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestMembersAndMethodCalls(t *testing.T) {
+	define := `let p = {"x": 1, "name": "origin", "shift": fn(n) { n + 10 }};
+	let twice = fn(xs, f) { [f(xs[0]), f(xs[1])] }; `
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"p.x", "1"},
+		{"p.name", "origin"},
+		{"p.missing", "null"},
+		{"p.shift(5)", "15"},
+		{"[1, 2, 3].len()", "3"},
+		{"[1, 2].push(3).len()", "3"},
+		{"[1, 2].twice(x => x * 3)", "[3, 6]"},
+		{"p.x = 5; p.x", "5"},
+		{"p.x += 1; p.x", "2"},
+		{"p.y = 7; p.y", "7"},
+		{"p.x.y", "ERROR: cannot access member y of INTEGER"},
+		{"p.x.y = 1", "ERROR: cannot assign to member y of INTEGER"},
+		{"5.nope()", "ERROR: INTEGER has no method nope"},
+		{"5 |> p.shift()", "15"},
+		{"4 |> [1, 2].push() |> len()", "3"},
+		{"1 |> p.missing()", "ERROR: HASH has no method missing"},
+	}
+	for _, tt := range tests {
+		got := testEval(define + tt.input).Inspect()
+		if got != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestClosures(t *testing.T) {
+	input := `
+let newAdder = fn(x) {
+  fn(y) { x + y };
+};
+let addTwo = newAdder(2);
+addTwo(2);`
+	testIntegerObject(t, testEval(input), 4)
+}
+
+func TestRecursiveFunction(t *testing.T) {
+	input := `
+let factorial = fn(n) {
+  if (n == 0) {
+    return 1;
+  } else {
+    return n * factorial(n - 1);
+  }
+};
+factorial(5);`
+	testIntegerObject(t, testEval(input), 120)
+}
+
+func TestStringLiteral(t *testing.T) {
+	input := `"Hello World!"`
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringConcatenation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"Hello" + " " + "World!"`, "Hello World!"},
+		{`let greet = fn(name) { "Hi, " + name + "\n" }; greet("Ana")`, "Hi, Ana\n"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+		}
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. expected=%q, got=%q", tt.expected, str.Value)
+		}
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" + "b" == "ab"`, true},
+	}
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d", len(result.Elements))
+	}
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]", 2},
+		{"[[1, 2], [3, 4]][1][0]", 3},
+		{"[1, 2, 3][3]", nil},
+		{"[1, 2, 3][-1]", nil},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("hello world")`, 11},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
+		{`len([1, 2, 3])`, 3},
+		{`len([])`, 0},
+		{`first([1, 2, 3])`, 1},
+		{`first([])`, nil},
+		{`first(1)`, "argument to `first` must be ARRAY, got INTEGER"},
+		{`last([1, 2, 3])`, 3},
+		{`last([])`, nil},
+		{`rest([1, 2, 3])`, []int{2, 3}},
+		{`rest([])`, nil},
+		{`push([], 1)`, []int{1}},
+		{`push(1, 1)`, "argument to `push` must be ARRAY, got INTEGER"},
+		{`let len = fn(x) { 42 }; len([1])`, 42},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		case []int:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("obj not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+				continue
+			}
+			for i, expectedElem := range expected {
+				testIntegerObject(t, array.Elements[i], int64(expectedElem))
+			}
+		}
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+{
+  "one": 10 - 9,
+  two: 1 + 1,
+  "thr" + "ee": 6 / 2,
+  4: 4,
+  true: 5,
+  false: 6
+}`
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		evaluator.TRUE.HashKey():                   5,
+		evaluator.FALSE.HashKey():                  6,
+	}
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+	if result.Inspect() != `{one: 1, two: 2, three: 3, 4: 4, true: 5, false: 6}` {
+		t.Errorf("hash is not in insertion order. got=%s", result.Inspect())
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`len({"a": 1, "b": 2})`, 2},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+// Helper function to lex, parse and evaluate input in a fresh environment
+func testEval(input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+	return evaluator.Eval(program, env)
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+// testInspect checks that obj, the result of evaluating input, inspects as
+// expected. Errors are compared as "ERROR: " and their message, without the
+// stack trace.
+func testInspect(t *testing.T, input string, obj object.Object, expected string) bool {
+	got := obj.Inspect()
+	if errObj, ok := obj.(*object.Error); ok {
+		got = "ERROR: " + errObj.Message
+	}
+	if got != expected {
+		t.Errorf("%q: expected=%s, got=%s", input, expected, got)
+		return false
+	}
+	return true
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != evaluator.NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
@@ -1,48 +1,110 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/TusharAbhinav/monkey/diagnostic"
 	token "github.com/TusharAbhinav/monkey/token"
 )
 
+// Diagnostic codes reported by the lexer
+const (
+	ErrIllegalCharacter    = "L0001" // a character that cannot start any token
+	ErrUnterminatedString  = "L0002" // a string literal without its closing quote
+	ErrInvalidEscape       = "L0003" // an unknown or malformed escape sequence in a string
+	ErrUnterminatedComment = "L0004" // a /* comment without its closing */
+	ErrInvalidUTF8         = "L0005" // bytes outside of string literals that are not valid UTF-8
+	ErrMalformedNumber     = "L0006" // a numeric literal that does not follow the number syntax
+)
+
+// Lexer turns UTF-8 source text into tokens. It reads the input one rune at
+// a time; positions are byte offsets, while columns count runes.
 type Lexer struct {
 	input        string
-	position     int
-	readPosition int
-	ch           byte
+	filename     string
+	position     int  // byte offset of ch
+	readPosition int  // byte offset just after ch
+	ch           rune // current character, 0 at the end of the input
+	line         int  // line of the current character
+	column       int  // column of the current character
+	errors       []diagnostic.Diagnostic
+	doc          []token.Token // doc comments read since the last token
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewWithFilename("", input)
+}
+
+// NewWithFilename creates a Lexer whose token positions carry filename.
+func NewWithFilename(filename, input string) *Lexer {
+	l := &Lexer{input: input, filename: filename, line: 1}
 	l.readChar()
 	return l
 }
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+	if l.readPosition > len(l.input) {
+		return // already at the end of the input
+	}
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
 	}
 	l.position = l.readPosition
-	l.readPosition++
+	l.column++
+	if l.readPosition == len(l.input) {
+		l.ch = 0
+		l.readPosition++
+		return
+	}
+	r, width := rune(l.input[l.readPosition]), 1
+	if r >= utf8.RuneSelf {
+		r, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
+	}
+	l.ch = r
+	l.readPosition += width
+}
+
+// invalidUTF8 reports whether the current character is a byte that does not
+// start a valid UTF-8 sequence (as opposed to an actual U+FFFD in the input).
+func (l *Lexer) invalidUTF8() bool {
+	return l.ch == utf8.RuneError && l.readPosition-l.position == 1
 }
 func (l *Lexer) NextToken() *token.Token {
-	skipWhitespace(l)
+	l.skipWhitespaceAndComments()
 
+	start := l.pos()
 	var tok token.Token
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: "=="}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: "=>"}
 		} else {
 			tok = token.Token{Type: token.ASSIGN, Literal: "="}
 		}
 	case '+':
-		tok = token.Token{Type: token.PLUS, Literal: "+"}
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.PLUS_ASSIGN, Literal: "+="}
+		} else {
+			tok = token.Token{Type: token.PLUS, Literal: "+"}
+		}
 	case '(':
 		tok = token.Token{Type: token.LPAREN, Literal: "("}
 	case '-':
-		tok = token.Token{Type: token.MINUS, Literal: "-"}
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.MINUS_ASSIGN, Literal: "-="}
+		} else {
+			tok = token.Token{Type: token.MINUS, Literal: "-"}
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			l.readChar()
@@ -51,79 +113,386 @@ func (l *Lexer) NextToken() *token.Token {
 			tok = token.Token{Type: token.BANG, Literal: "!"}
 		}
 	case '/':
-		tok = token.Token{Type: token.SLASH, Literal: "/"}
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.SLASH_ASSIGN, Literal: "/="}
+		} else {
+			tok = token.Token{Type: token.SLASH, Literal: "/"}
+		}
 	case '*':
-		tok = token.Token{Type: token.ASTERISK, Literal: "*"}
+		switch l.peekChar() {
+		case '*':
+			l.readChar()
+			tok = token.Token{Type: token.POWER, Literal: "**"}
+		case '=':
+			l.readChar()
+			tok = token.Token{Type: token.ASTERISK_ASSIGN, Literal: "*="}
+		default:
+			tok = token.Token{Type: token.ASTERISK, Literal: "*"}
+		}
+	case '%':
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.MODULO_ASSIGN, Literal: "%="}
+		} else {
+			tok = token.Token{Type: token.MODULO, Literal: "%"}
+		}
 	case '<':
-		tok = token.Token{Type: token.LT, Literal: "<"}
+		switch l.peekChar() {
+		case '=':
+			l.readChar()
+			tok = token.Token{Type: token.LT_EQ, Literal: "<="}
+		case '<':
+			l.readChar()
+			tok = token.Token{Type: token.SHL, Literal: "<<"}
+		default:
+			tok = token.Token{Type: token.LT, Literal: "<"}
+		}
 	case '>':
-		tok = token.Token{Type: token.GT, Literal: ">"}
+		switch l.peekChar() {
+		case '=':
+			l.readChar()
+			tok = token.Token{Type: token.GT_EQ, Literal: ">="}
+		case '>':
+			l.readChar()
+			tok = token.Token{Type: token.SHR, Literal: ">>"}
+		default:
+			tok = token.Token{Type: token.GT, Literal: ">"}
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: "&&"}
+		} else {
+			tok = token.Token{Type: token.BIT_AND, Literal: "&"}
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: "||"}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.PIPE, Literal: "|>"}
+		} else {
+			tok = token.Token{Type: token.BIT_OR, Literal: "|"}
+		}
+	case '^':
+		tok = token.Token{Type: token.BIT_XOR, Literal: "^"}
+	case '~':
+		tok = token.Token{Type: token.BIT_NOT, Literal: "~"}
 	case ')':
 		tok = token.Token{Type: token.RPAREN, Literal: ")"}
 	case '{':
 		tok = token.Token{Type: token.LBRACE, Literal: "{"}
 	case '}':
 		tok = token.Token{Type: token.RBRACE, Literal: "}"}
+	case '[':
+		tok = token.Token{Type: token.LBRACKET, Literal: "["}
+	case ']':
+		tok = token.Token{Type: token.RBRACKET, Literal: "]"}
 	case ',':
 		tok = token.Token{Type: token.COMMA, Literal: ","}
 	case ';':
 		tok = token.Token{Type: token.SEMICOLON, Literal: ";"}
+	case ':':
+		tok = token.Token{Type: token.COLON, Literal: ":"}
+	case '"':
+		value, ok := l.readString(start)
+		if !ok {
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[start.Offset:l.position]}
+			return l.withSpan(tok, start)
+		}
+		tok = token.Token{Type: token.STRING, Literal: value}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
+		return l.withSpan(tok, start)
 	default:
-		if isLetter(l.ch) {
+		if isIdentifierStart(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.ReadKeyword(tok.Literal)
-			return &tok
+			return l.withSpan(tok, start)
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return &tok
+			tok.Type, tok.Literal = l.readNumber(start)
+			return l.withSpan(tok, start)
+		} else if l.ch == '.' && l.peekChar() == '.' {
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.DOTDOT_EQ, Literal: "..="}
+			} else if l.peekChar() == '.' {
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			} else {
+				tok = token.Token{Type: token.DOTDOT, Literal: ".."}
+			}
+		} else if l.ch == '.' && isDigit(l.peekChar()) {
+			tok.Type, tok.Literal = l.readLeadingDotNumber(start)
+			return l.withSpan(tok, start)
+		} else if l.ch == '.' {
+			tok = token.Token{Type: token.DOT, Literal: "."}
 		} else {
-			tok = token.Token{Type: token.ILLEGAL, Literal: string(l.ch)}
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[l.position:l.readPosition]}
+			invalid := l.invalidUTF8()
+			l.readChar()
+			if invalid {
+				l.errorAt(start, l.pos(), ErrInvalidUTF8, "invalid UTF-8 encoding (byte %#x)", tok.Literal[0])
+			} else {
+				l.errorAt(start, l.pos(), ErrIllegalCharacter, "illegal character %q", tok.Literal)
+			}
+			return l.withSpan(tok, start)
 		}
 	}
 	l.readChar()
+	return l.withSpan(tok, start)
+}
+
+// pos returns the position of the current character.
+func (l *Lexer) pos() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
+
+// afterPos returns the position just past the current character.
+func (l *Lexer) afterPos() token.Position {
+	pos := l.pos()
+	if l.position < len(l.input) {
+		pos.Offset = l.readPosition
+		pos.Column++
+	}
+	return pos
+}
+
+// withSpan records that tok runs from start up to the current character,
+// and hands it the doc comments that preceded it.
+func (l *Lexer) withSpan(tok token.Token, start token.Position) *token.Token {
+	tok.Pos = start
+	tok.End = l.pos()
+	tok.Doc = l.doc
+	l.doc = nil
 	return &tok
 }
 
+// Errors returns the errors found in the input read so far.
+func (l *Lexer) Errors() []diagnostic.Diagnostic {
+	return l.errors
+}
+
+// errorAt records an error spanning from start to end.
+func (l *Lexer) errorAt(start, end token.Position, code string, format string, args ...interface{}) {
+	l.errors = append(l.errors, diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span:     diagnostic.Span{Start: start, End: end},
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// atEOF reports whether the whole input has been consumed.
+func (l *Lexer) atEOF() bool {
+	return l.position >= len(l.input)
+}
+
+// readString reads a string literal whose opening quote is at start and
+// returns its value with escape sequences decoded. It stops on the closing
+// quote, or at the end of the input, in which case ok is false.
+// Everything but escape sequences is copied byte for byte, so strings may
+// hold arbitrary bytes, including ones that are not valid UTF-8.
+func (l *Lexer) readString(start token.Position) (value string, ok bool) {
+	var out strings.Builder
+	for {
+		l.readChar()
+		switch {
+		case l.atEOF():
+			l.errorAt(start, l.pos(), ErrUnterminatedString, "unterminated string literal")
+			return out.String(), false
+		case l.ch == '"':
+			return out.String(), true
+		case l.ch == '\\':
+			l.readEscape(&out)
+		default:
+			out.WriteString(l.input[l.position:l.readPosition])
+		}
+	}
+}
+
+// readEscape decodes the escape sequence starting at the current backslash
+// into out, leaving the lexer on its last character. Invalid sequences are
+// reported and copied through unchanged.
+func (l *Lexer) readEscape(out *strings.Builder) {
+	start := l.pos()
+	if l.readPosition >= len(l.input) {
+		// leave it to readString to report the missing closing quote
+		return
+	}
+	l.readChar()
+	switch l.ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case '"':
+		out.WriteByte('"')
+	case '\\':
+		out.WriteByte('\\')
+	case 'u':
+		if r, ok := l.readUnicodeEscape(start); ok {
+			out.WriteRune(r)
+		} else {
+			out.WriteString(l.input[start.Offset:l.readPosition])
+		}
+	default:
+		l.errorAt(start, l.afterPos(), ErrInvalidEscape, "unknown escape sequence \\%c", l.ch)
+		out.WriteString(l.input[start.Offset:l.readPosition])
+	}
+}
+
+// readUnicodeEscape reads the {XXXX} part of a \u{XXXX} escape that
+// started at start and returns the code point it names.
+func (l *Lexer) readUnicodeEscape(start token.Position) (rune, bool) {
+	if l.peekChar() != '{' {
+		l.errorAt(start, l.afterPos(), ErrInvalidEscape, "\\u must be followed by {hex digits}")
+		return 0, false
+	}
+	l.readChar()
+	digits := l.readPosition
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+	}
+	hex := l.input[digits:l.readPosition]
+	if l.peekChar() != '}' {
+		l.errorAt(start, l.afterPos(), ErrInvalidEscape, "unterminated \\u{...} escape sequence")
+		return 0, false
+	}
+	l.readChar()
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) == 0 || len(hex) > 6 || err != nil || !utf8.ValidRune(rune(code)) {
+		l.errorAt(start, l.afterPos(), ErrInvalidEscape, "invalid Unicode code point \\u{%s}", hex)
+		return 0, false
+	}
+	return rune(code), true
+}
+
 func (l *Lexer) readIdentifier() string {
 	start := l.position
-	for isLetter(l.ch) {
+	for isIdentifierContinue(l.ch) {
 		l.readChar()
 	}
 	return l.input[start:l.position]
 }
-func (l *Lexer) readNumber() string {
-	start := l.position
-	for isDigit(l.ch) {
+
+// skipWhitespaceAndComments skips everything between two tokens. Line (//)
+// and block (/* */, which nest) comments are dropped, while /// doc comments
+// are kept for the next token.
+func (l *Lexer) skipWhitespaceAndComments() {
+	for {
+		skipWhitespace(l)
+		switch {
+		case l.ch == '/' && l.peekChar() == '/':
+			if l.peekByteAt(2) == '/' && l.peekByteAt(3) != '/' {
+				l.doc = append(l.doc, l.readDocComment())
+			} else {
+				l.skipLineComment()
+			}
+		case l.ch == '/' && l.peekChar() == '*':
+			l.skipBlockComment()
+		default:
+			return
+		}
+	}
+}
+
+// skipLineComment skips up to, but not including, the end of the line.
+func (l *Lexer) skipLineComment() {
+	for l.ch != '\n' && !l.atEOF() {
 		l.readChar()
 	}
-	return l.input[start:l.position]
 }
+
+// readDocComment reads a /// comment into a DOC_COMMENT token whose literal
+// is the text after the slashes, without the first space.
+func (l *Lexer) readDocComment() token.Token {
+	start := l.pos()
+	l.skipLineComment()
+	text := strings.TrimSuffix(l.input[start.Offset+3:l.position], "\r")
+	text = strings.TrimPrefix(text, " ")
+	return token.Token{Type: token.DOC_COMMENT, Literal: text, Pos: start, End: l.pos()}
+}
+
+// skipBlockComment skips a /* */ comment, including any comments nested in it.
+func (l *Lexer) skipBlockComment() {
+	start := l.pos()
+	depth := 0
+	for !l.atEOF() {
+		switch {
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return
+			}
+		}
+		l.readChar()
+	}
+	l.errorAt(start, l.pos(), ErrUnterminatedComment, "unterminated block comment")
+}
+
 func skipWhitespace(l *Lexer) {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
 		l.readChar()
 	}
 }
-func isDigit(ch uint8) bool {
+func isDigit(ch rune) bool {
 	if ch >= '0' && ch <= '9' {
 		return true
 	}
 	return false
 }
-func isLetter(ch uint8) bool {
-	if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' {
-		return true
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F'
+}
+
+// isIdentifierStart reports whether ch can start an identifier: an
+// underscore or a character with the Unicode ID_Start property (UAX #31).
+func isIdentifierStart(ch rune) bool {
+	if ch < utf8.RuneSelf {
+		return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
 	}
-	return false
+	return unicode.IsLetter(ch) || unicode.Is(unicode.Nl, ch) ||
+		unicode.Is(unicode.Other_ID_Start, ch)
+}
+
+// isIdentifierContinue reports whether ch can appear after the first
+// character of an identifier (ID_Continue in UAX #31).
+func isIdentifierContinue(ch rune) bool {
+	if ch < utf8.RuneSelf {
+		return isIdentifierStart(ch) || isDigit(ch)
+	}
+	return isIdentifierStart(ch) ||
+		unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)
 }
-func (l *Lexer) peekChar() byte {
+
+// peekChar returns the character after the current one without consuming it.
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
+}
+
+// peekByteAt returns the byte n bytes after the start of the current character.
+func (l *Lexer) peekByteAt(n int) byte {
+	if l.position+n >= len(l.input) {
+		return 0
 	}
-	return l.ch
+	return l.input[l.position+n]
 }
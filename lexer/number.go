@@ -0,0 +1,224 @@
+package lexer
+
+import (
+	"strings"
+
+	token "github.com/TusharAbhinav/monkey/token"
+)
+
+// readNumber reads the numeric literal whose first digit is at start and
+// returns its token type and spelling, which is kept as written:
+//
+//	42        INT
+//	1_000_000 INT
+//	0xFF      INT
+//	0o755     INT
+//	0b1010    INT
+//	3.14      FLOAT
+//	1e-9      FLOAT
+//	2.5E+3    FLOAT
+//	3r        RATIONAL
+//	12.50d    DECIMAL
+//
+// A '.' only belongs to the number when a digit follows it, so 1..5 and
+// 7.foo keep their dots. A '.' followed by a digit always starts a number,
+// which makes xs.1 the name xs and the malformed literal .1. Malformed
+// literals are reported and returned as ILLEGAL so that the parser does
+// not report them a second time.
+func (l *Lexer) readNumber(start token.Position) (token.TokenType, string) {
+	if l.ch == '0' {
+		if base, ok := basePrefixes[l.peekChar()]; ok {
+			return l.readPrefixedInteger(start, base)
+		}
+	}
+
+	tokenType := token.TokenType(token.INT)
+	ok := l.readDigits()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		ok = l.readDigits() && ok
+	}
+	hasExponent := l.ch == 'e' || l.ch == 'E'
+	if hasExponent {
+		tokenType = token.FLOAT
+		ok = l.readExponent(start) && ok
+	}
+
+	literal := l.input[start.Offset:l.position]
+	if ok && tokenType == token.INT && len(literal) > 1 && literal[0] == '0' {
+		if digits, isOctal := octalDigits(literal); isOctal {
+			l.errorAt(start, l.pos(), ErrMalformedNumber,
+				"decimal literal %s cannot start with 0, write 0o%s for an octal number",
+				literal, digits)
+		} else {
+			l.errorAt(start, l.pos(), ErrMalformedNumber,
+				"decimal literal %s cannot start with 0", literal)
+		}
+		ok = false
+	}
+
+	// an r or d suffix turns the number into an exact rational or decimal
+	if (l.ch == 'r' || l.ch == 'd') && !isIdentifierContinue(l.peekChar()) {
+		suffix := l.ch
+		l.readChar()
+		literal = l.input[start.Offset:l.position]
+		switch {
+		case suffix == 'r' && tokenType == token.FLOAT && ok:
+			l.errorAt(start, l.pos(), ErrMalformedNumber,
+				"rational literal %s must be a whole number, write a fraction such as 3/2r", literal)
+			ok = false
+		case suffix == 'd' && hasExponent && ok:
+			l.errorAt(start, l.pos(), ErrMalformedNumber,
+				"decimal literal %s cannot have an exponent", literal)
+			ok = false
+		}
+		tokenType = token.RATIONAL
+		if suffix == 'd' {
+			tokenType = token.DECIMAL
+		}
+	}
+	if !ok {
+		return token.ILLEGAL, literal
+	}
+	return tokenType, literal
+}
+
+// numberBase describes the digits allowed after a 0x, 0o or 0b prefix.
+type numberBase struct {
+	name  string
+	radix int
+}
+
+var basePrefixes = map[rune]numberBase{
+	'x': {"hexadecimal", 16},
+	'X': {"hexadecimal", 16},
+	'o': {"octal", 8},
+	'O': {"octal", 8},
+	'b': {"binary", 2},
+	'B': {"binary", 2},
+}
+
+// readPrefixedInteger reads an integer with a 0x, 0o or 0b prefix. All
+// letters and digits up to the end of the literal are consumed so that a
+// digit outside of the base, as in 0b102 or 0xFG, is reported where it
+// occurs instead of starting a new token.
+func (l *Lexer) readPrefixedInteger(start token.Position, base numberBase) (token.TokenType, string) {
+	l.readChar()
+	prev := l.ch // the prefix may be followed by a separator: 0x_FF
+	l.readChar()
+
+	ok := true
+	digits := 0
+	for l.ch == '_' || isIdentifierContinue(l.ch) {
+		switch {
+		case l.ch == '_':
+			next := l.peekChar()
+			ok = l.checkSeparator(prev, next != '_' && isIdentifierContinue(next)) && ok
+		case digitValue(l.ch) >= base.radix:
+			l.errorAt(l.pos(), l.afterPos(), ErrMalformedNumber,
+				"invalid digit %q in %s literal", l.ch, base.name)
+			ok = false
+		default:
+			digits++
+		}
+		prev = l.ch
+		l.readChar()
+	}
+	literal := l.input[start.Offset:l.position]
+
+	if digits == 0 && ok {
+		l.errorAt(start, l.pos(), ErrMalformedNumber,
+			"%s literal %s has no digits", base.name, literal)
+		ok = false
+	}
+	if !ok {
+		return token.ILLEGAL, literal
+	}
+	return token.INT, literal
+}
+
+// readLeadingDotNumber reads a literal like .5, which is not allowed, so that
+// it can be reported once as a whole instead of as a stray '.'.
+func (l *Lexer) readLeadingDotNumber(start token.Position) (token.TokenType, string) {
+	l.readChar()
+	l.readDigits()
+	if l.ch == 'e' || l.ch == 'E' {
+		l.readExponent(start)
+	}
+	literal := l.input[start.Offset:l.position]
+	l.errorAt(start, l.pos(), ErrMalformedNumber,
+		"floating-point literal %s must start with a digit, write 0%s", literal, literal)
+	return token.ILLEGAL, literal
+}
+
+// readExponent reads the e[+-]digits part of a float literal starting at
+// start, reporting an exponent without digits.
+func (l *Lexer) readExponent(start token.Position) bool {
+	l.readChar()
+	if l.ch == '+' || l.ch == '-' {
+		l.readChar()
+	}
+	if !isDigit(l.ch) {
+		l.errorAt(start, l.pos(), ErrMalformedNumber,
+			"exponent of %s has no digits", l.input[start.Offset:l.position])
+		return false
+	}
+	l.readDigits()
+	return true
+}
+
+// readDigits reads a run of decimal digits, which may be separated by
+// single underscores as in 1_000_000.
+func (l *Lexer) readDigits() bool {
+	ok := true
+	prev := rune(0)
+	for isDigit(l.ch) || l.ch == '_' {
+		if l.ch == '_' {
+			ok = l.checkSeparator(prev, isDigit(l.peekChar())) && ok
+		}
+		prev = l.ch
+		l.readChar()
+	}
+	return ok
+}
+
+// checkSeparator reports an underscore at the current character that is
+// not placed between two digits; prev is the character before it and
+// digitNext tells whether a digit follows it.
+func (l *Lexer) checkSeparator(prev rune, digitNext bool) bool {
+	if prev == '_' {
+		// already reported for the first underscore of the run
+		return false
+	}
+	if prev == 0 || !digitNext {
+		l.errorAt(l.pos(), l.afterPos(), ErrMalformedNumber, "'_' must separate successive digits")
+		return false
+	}
+	return true
+}
+
+// digitValue returns the value of ch as a digit in bases up to 36, or 36
+// if ch is not a digit at all.
+func digitValue(ch rune) int {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return int(ch - '0')
+	case 'a' <= ch && ch <= 'z':
+		return int(ch-'a') + 10
+	case 'A' <= ch && ch <= 'Z':
+		return int(ch-'A') + 10
+	}
+	return 36
+}
+
+// octalDigits returns the digits of a decimal literal with a leading zero
+// as they would be written after a 0o prefix, and whether they are all
+// valid octal digits.
+func octalDigits(literal string) (string, bool) {
+	digits := strings.TrimLeft(literal, "0_")
+	if digits == "" {
+		return "0", true
+	}
+	return digits, strings.Trim(digits, "01234567_") == ""
+}
@@ -96,7 +96,8 @@ func TestLargeNumbers(t *testing.T) {
 10;
 5555555;
 999999;
-0;`
+0;
+123456789012345678901234567890;`
 
 	tests := []struct {
 		expectedType    token.TokenType
@@ -112,6 +113,8 @@ func TestLargeNumbers(t *testing.T) {
 		{token.SEMICOLON, ";"},
 		{token.INT, "0"},
 		{token.SEMICOLON, ";"},
+		{token.INT, "123456789012345678901234567890"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -446,6 +449,719 @@ let else_block = 30;`
 	runLexerTest(t, input, tests)
 }
 
+// TestStringLiterals tests string literals and their escape sequences
+func TestStringLiterals(t *testing.T) {
+	input := `"foobar"
+"foo bar"
+"";
+"line\nbreak\ttab"
+"say \"hi\" \\o/"
+"\u{48}\u{e9}\u{1F600}"
+"café"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "foobar"},
+		{token.STRING, "foo bar"},
+		{token.STRING, ""},
+		{token.SEMICOLON, ";"},
+		{token.STRING, "line\nbreak\ttab"},
+		{token.STRING, `say "hi" \o/`},
+		{token.STRING, "H\u00e9\U0001F600"},
+		{token.STRING, "caf\u00e9"},
+		{token.EOF, ""},
+	}
+
+	runLexerTest(t, input, tests)
+}
+
+// TestStringErrors tests the errors reported for malformed string literals
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  token.TokenType
+		expectedCodes []string
+		expectedStart int
+		expectedEnd   int
+	}{
+		{`"abc`, token.ILLEGAL, []string{lexer.ErrUnterminatedString}, 0, 4},
+		{`"a\qb"`, token.STRING, []string{lexer.ErrInvalidEscape}, 2, 4},
+		{`"\u{110000}"`, token.STRING, []string{lexer.ErrInvalidEscape}, 1, 11},
+		{`"\u{zz}"`, token.STRING, []string{lexer.ErrInvalidEscape}, 1, 4},
+		{`"\u41"`, token.STRING, []string{lexer.ErrInvalidEscape}, 1, 3},
+		{`"abc\`, token.ILLEGAL, []string{lexer.ErrUnterminatedString}, 0, 5},
+		{`@`, token.ILLEGAL, []string{lexer.ErrIllegalCharacter}, 0, 1},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Errorf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		errors := l.Errors()
+		if len(errors) != len(tt.expectedCodes) {
+			t.Errorf("tests[%d] - wrong number of errors. expected=%d, got=%d (%v)",
+				i, len(tt.expectedCodes), len(errors), errors)
+			continue
+		}
+		for j, code := range tt.expectedCodes {
+			if errors[j].Code != code {
+				t.Errorf("tests[%d] - errors[%d] code wrong. expected=%s, got=%s",
+					i, j, code, errors[j].Code)
+			}
+		}
+		span := errors[0].Span
+		if span.Start.Offset != tt.expectedStart || span.End.Offset != tt.expectedEnd {
+			t.Errorf("tests[%d] - span wrong. expected=[%d,%d), got=[%d,%d)", i,
+				tt.expectedStart, tt.expectedEnd, span.Start.Offset, span.End.Offset)
+		}
+	}
+}
+
+// TestArrayTokens tests brackets used by array literals and index expressions
+func TestArrayTokens(t *testing.T) {
+	input := `[1, 2][0];`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	runLexerTest(t, input, tests)
+}
+
+// TestHashTokens tests the colon separating hash keys from values
+func TestHashTokens(t *testing.T) {
+	input := `{"foo": "bar", 1: true}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LBRACE, "{"},
+		{token.STRING, "foo"},
+		{token.COLON, ":"},
+		{token.STRING, "bar"},
+		{token.COMMA, ","},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.TRUE, "true"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	runLexerTest(t, input, tests)
+}
+
+// TestComments tests that line and (nested) block comments are skipped
+func TestComments(t *testing.T) {
+	input := `// a line comment
+let x = 10 / 2; // trailing comment
+/* a block
+   comment */ let y /* inline */ = x;
+/* outer /* nested */ still a comment */ y
+//// four slashes is an ordinary comment
+x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "y"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	runLexerTest(t, input, tests)
+}
+
+// TestDocComments tests that /// comments are attached to the following token
+func TestDocComments(t *testing.T) {
+	input := `/// Adds two numbers.
+///
+///Returns their sum.
+let add = 1; // not documentation
+let sub = 2;`
+
+	l := lexer.New(input)
+	tok := l.NextToken()
+	if tok.Type != token.LET {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.LET, tok.Type)
+	}
+	expected := []string{"Adds two numbers.", "", "Returns their sum."}
+	if len(tok.Doc) != len(expected) {
+		t.Fatalf("wrong number of doc comments. expected=%d, got=%d", len(expected), len(tok.Doc))
+	}
+	for i, text := range expected {
+		if tok.Doc[i].Type != token.DOC_COMMENT || tok.Doc[i].Literal != text {
+			t.Errorf("Doc[%d] wrong. expected=%q, got=%s %q", i, text, tok.Doc[i].Type, tok.Doc[i].Literal)
+		}
+	}
+	if tok.Doc[2].Pos.Line != 3 || tok.Doc[2].Pos.Column != 1 {
+		t.Errorf("Doc[2] position wrong. got=%s", tok.Doc[2].Pos)
+	}
+	for tok.Type != token.EOF {
+		tok = l.NextToken()
+		if len(tok.Doc) != 0 {
+			t.Errorf("token %q has unexpected doc comments %v", tok.Literal, tok.Doc)
+		}
+	}
+}
+
+// TestUnterminatedBlockComment tests the error for a block comment missing its */
+func TestUnterminatedBlockComment(t *testing.T) {
+	input := `let x = 1; /* /* nested */ never closed`
+	l := lexer.New(input)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+	errors := l.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("wrong number of errors. expected=1, got=%d", len(errors))
+	}
+	if errors[0].Code != lexer.ErrUnterminatedComment {
+		t.Errorf("error code wrong. expected=%s, got=%s", lexer.ErrUnterminatedComment, errors[0].Code)
+	}
+	if errors[0].Span.Start.Offset != 11 {
+		t.Errorf("error start wrong. expected=11, got=%d", errors[0].Span.Start.Offset)
+	}
+}
+
+// TestUnicodeIdentifiers tests identifiers made of non-ASCII letters and digits
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let π = 3;
+let café = "naïve";
+let 変数2 = x1_٣;
+let ǅx = Ⅻ;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "π"},
+		{token.ASSIGN, "="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "café"},
+		{token.ASSIGN, "="},
+		{token.STRING, "naïve"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "変数2"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "x1_٣"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "ǅx"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "Ⅻ"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	runLexerTest(t, input, tests)
+}
+
+// TestUnicodeSymbolsAreIllegal tests that non-ASCII characters which are not
+// identifier characters become single ILLEGAL tokens
+func TestUnicodeSymbolsAreIllegal(t *testing.T) {
+	input := `a → b`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.ILLEGAL, "→"},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	runLexerTest(t, input, tests)
+}
+
+// TestUnicodeColumns tests that columns count characters, not bytes
+func TestUnicodeColumns(t *testing.T) {
+	input := "let ñandú = \"日本\" + 変数;"
+
+	tests := []struct {
+		expectedLiteral string
+		column, offset  int
+	}{
+		{"let", 1, 0},
+		{"ñandú", 5, 4},
+		{"=", 11, 12},
+		{"日本", 13, 14},
+		{"+", 18, 23},
+		{"変数", 20, 25},
+		{";", 22, 31},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Column != tt.column || tok.Pos.Offset != tt.offset {
+			t.Errorf("tests[%d] - position wrong. expected column %d offset %d, got column %d offset %d",
+				i, tt.column, tt.offset, tok.Pos.Column, tok.Pos.Offset)
+		}
+	}
+}
+
+// TestInvalidUTF8 tests that invalid UTF-8 is reported with its position,
+// except inside string literals, which keep their bytes unchanged
+func TestInvalidUTF8(t *testing.T) {
+	input := "let x = \"a\xffb\";\nlet \xc3y = 1;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.STRING, "a\xffb"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.ILLEGAL, "\xc3"},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+	errors := l.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("wrong number of errors. expected=1, got=%d (%v)", len(errors), errors)
+	}
+	if errors[0].Code != lexer.ErrInvalidUTF8 {
+		t.Errorf("error code wrong. expected=%s, got=%s", lexer.ErrInvalidUTF8, errors[0].Code)
+	}
+	if pos := errors[0].Span.Start; pos.Line != 2 || pos.Column != 5 || pos.Offset != 19 {
+		t.Errorf("error position wrong. expected 2:5 (offset 19), got=%s (offset %d)", pos, pos.Offset)
+	}
+}
+
+func TestFloatLiterals(t *testing.T) {
+	input := `3.14 1e-9 2.5E+3 10e2 7.foo`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2.5E+3"},
+		{token.FLOAT, "10e2"},
+		{token.INT, "7"},
+		{token.DOT, "."},
+		{token.IDENT, "foo"},
+		{token.EOF, ""},
+	})
+}
+
+func TestDotBeforeDigitStartsNumber(t *testing.T) {
+	l := lexer.New("xs.1")
+	for _, expected := range []token.Token{
+		{Type: token.IDENT, Literal: "xs"},
+		{Type: token.ILLEGAL, Literal: ".1"},
+		{Type: token.EOF, Literal: ""},
+	} {
+		tok := l.NextToken()
+		if tok.Type != expected.Type || tok.Literal != expected.Literal {
+			t.Fatalf("token wrong. expected=%s %q, got=%s %q", expected.Type, expected.Literal, tok.Type, tok.Literal)
+		}
+	}
+	errors := l.Errors()
+	if len(errors) != 1 || errors[0].Code != lexer.ErrMalformedNumber {
+		t.Fatalf("expected one %s error, got=%v", lexer.ErrMalformedNumber, errors)
+	}
+}
+
+func TestMalformedFloatLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedMessage string
+	}{
+		{".5", ".5", "floating-point literal .5 must start with a digit, write 0.5"},
+		{"1e", "1e", "exponent of 1e has no digits"},
+		{"2.0e+", "2.0e+", "exponent of 2.0e+ has no digits"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.expectedLiteral {
+			t.Errorf("%q: token wrong. expected=ILLEGAL %q, got=%s %q",
+				tt.input, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Code != lexer.ErrMalformedNumber {
+			t.Errorf("%q: error code wrong. expected=%s, got=%s", tt.input, lexer.ErrMalformedNumber, errors[0].Code)
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q: error message wrong. expected=%q, got=%q", tt.input, tt.expectedMessage, errors[0].Message)
+		}
+	}
+}
+
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	input := `a <= b >= c && d || !e < f > g`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.AND, "&&"},
+		{token.IDENT, "d"},
+		{token.OR, "||"},
+		{token.BANG, "!"},
+		{token.IDENT, "e"},
+		{token.LT, "<"},
+		{token.IDENT, "f"},
+		{token.GT, ">"},
+		{token.IDENT, "g"},
+		{token.EOF, ""},
+	})
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	input := `a % b ** c * d & e | f ^ ~g << 2 >> 1`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.MODULO, "%"},
+		{token.IDENT, "b"},
+		{token.POWER, "**"},
+		{token.IDENT, "c"},
+		{token.ASTERISK, "*"},
+		{token.IDENT, "d"},
+		{token.BIT_AND, "&"},
+		{token.IDENT, "e"},
+		{token.BIT_OR, "|"},
+		{token.IDENT, "f"},
+		{token.BIT_XOR, "^"},
+		{token.BIT_NOT, "~"},
+		{token.IDENT, "g"},
+		{token.SHL, "<<"},
+		{token.INT, "2"},
+		{token.SHR, ">>"},
+		{token.INT, "1"},
+		{token.EOF, ""},
+	})
+}
+
+func TestAssignmentOperators(t *testing.T) {
+	input := `x = 1; x += 2; x -= 3; x *= 4; x /= 5; x %= 6; x ** 2;`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MODULO_ASSIGN, "%="},
+		{token.INT, "6"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.POWER, "**"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	})
+}
+
+func TestLoopKeywords(t *testing.T) {
+	input := `while for break continue whiles in`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.WHILE, "while"},
+		{token.FOR, "for"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+		{token.IDENT, "whiles"},
+		{token.IN, "in"},
+		{token.EOF, ""},
+	})
+}
+
+func TestRangeOperators(t *testing.T) {
+	input := `0..10 1..=n xs[1:-1] .5`
+
+	l := lexer.New(input)
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0"},
+		{token.DOTDOT, ".."},
+		{token.INT, "10"},
+		{token.INT, "1"},
+		{token.DOTDOT_EQ, "..="},
+		{token.IDENT, "n"},
+		{token.IDENT, "xs"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.RBRACKET, "]"},
+		{token.ILLEGAL, ".5"},
+		{token.EOF, ""},
+	}
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestMatchLambdaPipeAndDotTokens(t *testing.T) {
+	input := `match (v) { [x, ...rest] => x, _ => 0 } a = > xs |> f() | g p.x`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "v"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "x"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RBRACKET, "]"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "x"},
+		{token.COMMA, ","},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "0"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "a"},
+		{token.ASSIGN, "="},
+		{token.GT, ">"},
+		{token.IDENT, "xs"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.BIT_OR, "|"},
+		{token.IDENT, "g"},
+		{token.IDENT, "p"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	})
+}
+
+func TestIntegerLiteralBases(t *testing.T) {
+	input := `0xFF 0Xab_cd 0o755 0b1010 0b_1 1_000_000 0 1_000.5`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0Xab_cd"},
+		{token.INT, "0o755"},
+		{token.INT, "0b1010"},
+		{token.INT, "0b_1"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0"},
+		{token.FLOAT, "1_000.5"},
+		{token.EOF, ""},
+	})
+}
+
+func TestExactNumberLiterals(t *testing.T) {
+	input := `1/3r 12.50d 5d 1_000.00d 3rd`
+
+	runLexerTest(t, input, []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "1"},
+		{token.SLASH, "/"},
+		{token.RATIONAL, "3r"},
+		{token.DECIMAL, "12.50d"},
+		{token.DECIMAL, "5d"},
+		{token.DECIMAL, "1_000.00d"},
+		{token.INT, "3"},
+		{token.IDENT, "rd"},
+		{token.EOF, ""},
+	})
+}
+
+func TestMalformedIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  int
+	}{
+		{"0b102", "invalid digit '2' in binary literal", 5},
+		{"0o78", "invalid digit '8' in octal literal", 4},
+		{"0xFG", "invalid digit 'G' in hexadecimal literal", 4},
+		{"0x", "hexadecimal literal 0x has no digits", 1},
+		{"1__000", "'_' must separate successive digits", 2},
+		{"1000_", "'_' must separate successive digits", 5},
+		{"0xFF_", "'_' must separate successive digits", 5},
+		{"0755", "decimal literal 0755 cannot start with 0, write 0o755 for an octal number", 1},
+		{"09", "decimal literal 09 cannot start with 0", 1},
+		{"0_789", "decimal literal 0_789 cannot start with 0", 1},
+		{"1.5r", "rational literal 1.5r must be a whole number, write a fraction such as 3/2r", 1},
+		{"1e3d", "decimal literal 1e3d cannot have an exponent", 1},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.input {
+			t.Errorf("%q: token wrong. expected=ILLEGAL %q, got=%s %q",
+				tt.input, tt.input, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("%q: literal was not read as a whole, next token=%s %q", tt.input, next.Type, next.Literal)
+		}
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q: error message wrong. expected=%q, got=%q", tt.input, tt.expectedMessage, errors[0].Message)
+		}
+		if errors[0].Span.Start.Column != tt.expectedColumn {
+			t.Errorf("%q: error column wrong. expected=%d, got=%d", tt.input, tt.expectedColumn, errors[0].Span.Start.Column)
+		}
+	}
+}
+
+// TestTokenPositions tests the line, column and byte span recorded on each token
+func TestTokenPositions(t *testing.T) {
+	input := `let five = 5;
+  add(five, 10) == 15;`
+
+	tests := []struct {
+		expectedLiteral string
+		line, column    int
+		offset, end     int
+	}{
+		{"let", 1, 1, 0, 3},
+		{"five", 1, 5, 4, 8},
+		{"=", 1, 10, 9, 10},
+		{"5", 1, 12, 11, 12},
+		{";", 1, 13, 12, 13},
+		{"add", 2, 3, 16, 19},
+		{"(", 2, 6, 19, 20},
+		{"five", 2, 7, 20, 24},
+		{",", 2, 11, 24, 25},
+		{"10", 2, 13, 26, 28},
+		{")", 2, 15, 28, 29},
+		{"==", 2, 17, 30, 32},
+		{"15", 2, 20, 33, 35},
+		{";", 2, 22, 35, 36},
+		{"", 2, 23, 36, 36},
+	}
+
+	l := lexer.NewWithFilename("test.mk", input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Filename != "test.mk" {
+			t.Errorf("tests[%d] - filename wrong. got=%q", i, tok.Pos.Filename)
+		}
+		if tok.Pos.Line != tt.line || tok.Pos.Column != tt.column {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.line, tt.column, tok.Pos.Line, tok.Pos.Column)
+		}
+		if tok.Pos.Offset != tt.offset || tok.End.Offset != tt.end {
+			t.Errorf("tests[%d] - span wrong. expected=[%d,%d), got=[%d,%d)",
+				i, tt.offset, tt.end, tok.Pos.Offset, tok.End.Offset)
+		}
+	}
+}
+
 // Helper function to run lexer tests
 func runLexerTest(t *testing.T, input string, tests []struct {
 	expectedType    token.TokenType
@@ -0,0 +1,134 @@
+package object
+
+import (
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+)
+
+// Iterable is implemented by the objects a for-in loop can walk over.
+type Iterable interface {
+	Object
+	Iterate() Iterator
+}
+
+// Iterator produces the entries of an Iterable one at a time. Each entry
+// has a key and a value: the index and the element for arrays, strings and
+// ranges, the key and the value for hashes.
+type Iterator interface {
+	// Next returns the next entry, or ok == false once there are no more.
+	Next() (key, value Object, ok bool)
+}
+
+// Iterate walks the elements of the array. Elements assigned while the
+// loop runs are seen by later iterations.
+func (a *Array) Iterate() Iterator { return &arrayIterator{array: a} }
+
+type arrayIterator struct {
+	array *Array
+	index int
+}
+
+func (it *arrayIterator) Next() (Object, Object, bool) {
+	if it.index >= len(it.array.Elements) {
+		return nil, nil, false
+	}
+	key := &Integer{Value: int64(it.index)}
+	it.index++
+	return key, it.array.Elements[it.index-1], true
+}
+
+// Iterate walks the pairs of the hash in insertion order.
+func (h *Hash) Iterate() Iterator { return &hashIterator{hash: h} }
+
+type hashIterator struct {
+	hash  *Hash
+	index int
+}
+
+func (it *hashIterator) Next() (Object, Object, bool) {
+	for it.index < len(it.hash.Order) {
+		pair, ok := it.hash.Pairs[it.hash.Order[it.index]]
+		it.index++
+		if ok {
+			return pair.Key, pair.Value, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Iterate walks the runes of the string. The key of each rune is its
+// index counted in runes, and invalid UTF-8 bytes come out one at a time.
+func (s *String) Iterate() Iterator { return &stringIterator{value: s.Value} }
+
+type stringIterator struct {
+	value  string
+	offset int
+	index  int
+}
+
+func (it *stringIterator) Next() (Object, Object, bool) {
+	if it.offset >= len(it.value) {
+		return nil, nil, false
+	}
+	_, size := utf8.DecodeRuneInString(it.value[it.offset:])
+	key := &Integer{Value: int64(it.index)}
+	value := &String{Value: it.value[it.offset : it.offset+size]}
+	it.offset += size
+	it.index++
+	return key, value, true
+}
+
+// Range implements Object interface. It stands for the integers from Start
+// up to End, which is only included when Inclusive is set. The integers
+// are only produced while iterating, so 0..1000000000 takes no memory.
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Inclusive {
+		return fmt.Sprintf("%d..=%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
+
+// Len returns the number of integers in the range. It is computed with
+// big.Int because a range such as 0..=9223372036854775807 holds more
+// integers than an int64 can count.
+func (r *Range) Len() *big.Int {
+	n := new(big.Int).Sub(big.NewInt(r.End), big.NewInt(r.Start))
+	if r.Inclusive {
+		n.Add(n, big.NewInt(1))
+	}
+	if n.Sign() < 0 {
+		n.SetInt64(0)
+	}
+	return n
+}
+
+// Iterate produces the integers of the range in increasing order.
+func (r *Range) Iterate() Iterator { return &rangeIterator{r: r, next: r.Start} }
+
+type rangeIterator struct {
+	r    *Range
+	next int64
+	done bool // set once next would overflow past the end of an inclusive range
+}
+
+func (it *rangeIterator) Next() (Object, Object, bool) {
+	if it.done || it.next > it.r.End || it.next == it.r.End && !it.r.Inclusive {
+		return nil, nil, false
+	}
+	key := &Integer{Value: it.next - it.r.Start}
+	value := &Integer{Value: it.next}
+	if it.next == it.r.End {
+		it.done = true
+	} else {
+		it.next++
+	}
+	return key, value, true
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/TusharAbhinav/monkey/object"
+)
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &object.String{Value: "Hello World"}
+	hello2 := &object.String{Value: "Hello World"}
+	diff1 := &object.String{Value: "My name is johnny"}
+	diff2 := &object.String{Value: "My name is johnny"}
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+}
+
+func TestHashKeysDependOnType(t *testing.T) {
+	one := &object.Integer{Value: 1}
+	yes := &object.Boolean{Value: true}
+	if one.HashKey() == yes.HashKey() {
+		t.Errorf("1 and true have the same hash key")
+	}
+	if one.HashKey() != (&object.Integer{Value: 1}).HashKey() {
+		t.Errorf("integers with same value have different hash keys")
+	}
+}
+
+func TestIterators(t *testing.T) {
+	hash := object.NewHash()
+	for _, key := range []string{"z", "a"} {
+		k := &object.String{Value: key}
+		hash.Set(k.HashKey(), object.HashPair{Key: k, Value: &object.Integer{Value: 1}})
+	}
+
+	tests := []struct {
+		iterable object.Iterable
+		expected []string
+	}{
+		{&object.Array{Elements: []object.Object{&object.Integer{Value: 5}, &object.Boolean{Value: true}}}, []string{"0:5", "1:true"}},
+		{hash, []string{"z:1", "a:1"}},
+		{&object.String{Value: "é!"}, []string{"0:é", "1:!"}},
+		{&object.Range{Start: 3, End: 5}, []string{"0:3", "1:4"}},
+		{&object.Range{Start: 5, End: 3}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		it := tt.iterable.Iterate()
+		for key, value, ok := it.Next(); ok; key, value, ok = it.Next() {
+			got = append(got, key.Inspect()+":"+value.Inspect())
+		}
+		if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("%s: expected=%v, got=%v", tt.iterable.Inspect(), tt.expected, got)
+		}
+	}
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	big1 := &object.BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 100)}
+	big2 := &object.BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 100)}
+	negative := &object.BigInteger{Value: new(big.Int).Neg(big1.Value)}
+	if big1.HashKey() != big2.HashKey() {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+	if big1.HashKey() == negative.HashKey() {
+		t.Errorf("big integers with opposite signs have same hash keys")
+	}
+}
+
+func TestHashKeepsInsertionOrder(t *testing.T) {
+	hash := object.NewHash()
+	keys := []object.Object{
+		&object.String{Value: "b"},
+		&object.Integer{Value: 1},
+		&object.String{Value: "a"},
+		&object.Boolean{Value: false},
+	}
+	for i, key := range keys {
+		hashKey := key.(object.Hashable).HashKey()
+		hash.Set(hashKey, object.HashPair{Key: key, Value: &object.Integer{Value: int64(i)}})
+	}
+	// replacing a value keeps the key where it was
+	hash.Set((&object.Integer{Value: 1}).HashKey(),
+		object.HashPair{Key: &object.Integer{Value: 1}, Value: &object.Integer{Value: 9}})
+
+	expected := `{b: 0, 1: 9, a: 2, false: 3}`
+	if hash.Inspect() != expected {
+		t.Errorf("hash.Inspect() wrong. expected=%q, got=%q", expected, hash.Inspect())
+	}
+}
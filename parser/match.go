@@ -0,0 +1,304 @@
+package parser
+
+import (
+	"github.com/TusharAbhinav/monkey/ast"
+	token "github.com/TusharAbhinav/monkey/token"
+)
+
+// ============================
+// MATCH EXPRESSION PARSERS
+// ============================
+
+// parseMatchExpression parses match (subject) { pattern => body, ... }.
+// Arms are separated by commas, which may be left out after an arm whose
+// body is a block.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	start := p.curToken
+	expression := &ast.MatchExpression{Token: *p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return p.badExpression(start)
+	}
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return p.badExpression(start)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return p.badExpression(start)
+	}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return p.badExpression(start)
+		}
+		expression.Arms = append(expression.Arms, arm)
+		if _, block := arm.Body.(*ast.BlockStatement); block && !p.peekTokenIs(token.COMMA) {
+			continue
+		}
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return p.badExpression(start)
+		}
+	}
+	p.nextToken()
+	expression.Rbrace = *p.curToken
+	p.checkMatchArms(expression)
+	return expression
+}
+
+// parseMatchArm parses pattern => body or pattern if guard => body. A body
+// starting with { is a block, so a hash literal body needs parentheses.
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	pattern := p.parsePattern()
+	if pattern == nil {
+		return nil
+	}
+	p.checkPatternBindings(pattern, false)
+	arm := &ast.MatchArm{Pattern: pattern}
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		// the => after the guard starts the body, not an arrow function
+		noArrow := p.noArrow
+		p.noArrow = true
+		arm.Guard = p.parseExpression(LOWEST)
+		p.noArrow = noArrow
+	}
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	p.nextToken()
+	if p.curTokenIs(token.LBRACE) {
+		arm.Body = p.parseBlockStatement()
+	} else {
+		arm.Body = p.parseExpression(LOWEST)
+	}
+	return arm
+}
+
+// ============================
+// PATTERN PARSERS
+// ============================
+
+// parsePattern parses the pattern starting at curToken. It returns nil
+// after reporting an error when there is no valid pattern there.
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.INT, token.FLOAT, token.RATIONAL, token.DECIMAL,
+		token.STRING, token.TRUE, token.FALSE:
+		return &ast.LiteralPattern{Value: p.prefixParseFns[p.curToken.Type]()}
+	case token.MINUS:
+		return p.parseNegativeLiteralPattern()
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: *p.curToken}
+		}
+		return &ast.BindingPattern{Name: &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}}
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	case token.ILLEGAL:
+		// already reported by the lexer
+		p.panicking = true
+		return nil
+	}
+	p.errorAt(p.curToken, ErrInvalidPattern,
+		[]string{"patterns are literals, names, _, [a, b] or {\"key\": value}"},
+		"expected a pattern, got %s", p.curToken.Type)
+	return nil
+}
+
+// parseNegativeLiteralPattern parses a minus sign followed by a number.
+func (p *Parser) parseNegativeLiteralPattern() ast.Pattern {
+	minus := p.curToken
+	switch p.peekToken.Type {
+	case token.INT, token.FLOAT, token.RATIONAL, token.DECIMAL:
+	default:
+		p.errorAt(p.peekToken, ErrInvalidPattern, nil,
+			"expected a number after - in a pattern, got %s", p.peekToken.Type)
+		return nil
+	}
+	p.nextToken()
+	value := &ast.PrefixExpression{
+		Token:    *minus,
+		Operator: "-",
+		Right:    p.prefixParseFns[p.curToken.Type](),
+	}
+	return &ast.LiteralPattern{Value: value}
+}
+
+// parseArrayPattern parses [a, b] or [first, ...rest]. The rest binding
+// has to be the last element.
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	array := &ast.ArrayPattern{Token: *p.curToken}
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			array.Rest = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+			if p.peekTokenIs(token.COMMA) {
+				p.nextToken()
+			}
+			if !p.peekTokenIs(token.RBRACKET) {
+				p.errorAt(p.peekToken, ErrInvalidPattern, nil,
+					"...%s must be the last element of an array pattern", array.Rest.Value)
+				return nil
+			}
+			p.nextToken()
+			array.Rbracket = *p.curToken
+			return array
+		}
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		array.Elements = append(array.Elements, element)
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+	array.Rbracket = *p.curToken
+	return array
+}
+
+// parseHashPattern parses {"key": pattern, ...}. Keys are names, which
+// stand for string keys, or string, integer and boolean literals. A name
+// without a pattern binds the value under that key to the name itself.
+func (p *Parser) parseHashPattern() ast.Pattern {
+	hash := &ast.HashPattern{Token: *p.curToken}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		var key ast.Expression
+		var value ast.Pattern
+		switch p.curToken.Type {
+		case token.IDENT:
+			name := &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+			key, value = name, &ast.BindingPattern{Name: name}
+		case token.STRING, token.INT, token.TRUE, token.FALSE:
+			key = p.prefixParseFns[p.curToken.Type]()
+		default:
+			p.errorAt(p.curToken, ErrInvalidPattern, nil,
+				"expected a name, string, integer or boolean key in a hash pattern, got %s", p.curToken.Type)
+			return nil
+		}
+		if value == nil || p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+			p.nextToken()
+			if value = p.parsePattern(); value == nil {
+				return nil
+			}
+		}
+		hash.Pairs = append(hash.Pairs, ast.HashPatternPair{Key: key, Value: value})
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+	hash.Rbrace = *p.curToken
+	return hash
+}
+
+// ============================
+// PATTERN CHECKS
+// ============================
+
+// checkPatternBindings reports names that pattern binds more than once.
+// Patterns of let statements may not contain literals either, since a let
+// cannot fall through to another arm when the value is different.
+func (p *Parser) checkPatternBindings(pattern ast.Pattern, inLet bool) {
+	seen := map[string]bool{}
+	bind := func(name *ast.Identifier) {
+		if name.Value == "_" {
+			return
+		}
+		if seen[name.Value] {
+			p.nodeError(name, ErrDuplicateBinding, nil,
+				"%s is bound more than once in this pattern", name.Value)
+		}
+		seen[name.Value] = true
+	}
+	var walk func(pattern ast.Pattern)
+	walk = func(pattern ast.Pattern) {
+		switch pattern := pattern.(type) {
+		case *ast.BindingPattern:
+			bind(pattern.Name)
+		case *ast.LiteralPattern:
+			if inLet {
+				p.nodeError(pattern, ErrInvalidPattern,
+					[]string{"use match to compare a value against literals"},
+					"cannot use literal %s in a let pattern", pattern)
+			}
+		case *ast.ArrayPattern:
+			for _, element := range pattern.Elements {
+				walk(element)
+			}
+			if pattern.Rest != nil {
+				bind(pattern.Rest)
+			}
+		case *ast.HashPattern:
+			for _, pair := range pattern.Pairs {
+				walk(pair.Value)
+			}
+		}
+	}
+	walk(pattern)
+}
+
+// ============================
+// MATCH ARM CHECKS
+// ============================
+
+// checkMatchArms warns about arms that can never be reached and about
+// matches that may not handle every value. An arm is unreachable after an
+// unguarded _ or binding arm, or when an earlier unguarded arm has the same
+// literal. A match counts as exhaustive once it has an unguarded _ or
+// binding arm, or unguarded arms for both true and false.
+func (p *Parser) checkMatchArms(match *ast.MatchExpression) {
+	if p.panicking {
+		return
+	}
+	catchAll := false
+	literals := map[string]bool{}
+	for _, arm := range match.Arms {
+		if catchAll {
+			p.nodeWarning(arm, WarnUnreachableArm,
+				[]string{"an earlier arm matches every value"},
+				"unreachable match arm")
+			continue
+		}
+		if lit, ok := arm.Pattern.(*ast.LiteralPattern); ok {
+			if literals[lit.String()] {
+				p.nodeWarning(arm, WarnUnreachableArm,
+					[]string{"an earlier arm already matches " + lit.String()},
+					"unreachable match arm")
+				continue
+			}
+			if arm.Guard == nil {
+				literals[lit.String()] = true
+			}
+		}
+		if arm.Guard == nil && isIrrefutable(arm.Pattern) {
+			catchAll = true
+		}
+	}
+	if !catchAll && !(literals["true"] && literals["false"]) {
+		p.nodeWarning(match, WarnNonExhaustive,
+			[]string{"add a `_ => ...` arm to handle the remaining values"},
+			"match is not exhaustive")
+	}
+}
+
+// isIrrefutable reports whether pattern matches every value.
+func isIrrefutable(pattern ast.Pattern) bool {
+	switch pattern.(type) {
+	case *ast.WildcardPattern, *ast.BindingPattern:
+		return true
+	}
+	return false
+}
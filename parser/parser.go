@@ -1,10 +1,15 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/TusharAbhinav/monkey/ast"
+	"github.com/TusharAbhinav/monkey/diagnostic"
 	"github.com/TusharAbhinav/monkey/lexer"
 	token "github.com/TusharAbhinav/monkey/token"
 )
@@ -19,33 +24,99 @@ type (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // = or +=
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
+	PIPE        // |>, below comparisons so x + 1 |> f() pipes x + 1
+	BITWISE_OR  // |
+	BITWISE_XOR // ^
+	BITWISE_AND // &
 	EQUALS      // ==
 	LESSGREATER // > or <
+	RANGE       // .. or ..=
+	SHIFT       // << or >>
 	SUM         // +
-	PRODUCT     // *
-	PREFIX      // -X or !X
+	PRODUCT     // * / %
+	PREFIX      // -X or !X or ~X
+	POWER       // **, so -x ** 2 is -(x ** 2)
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 // Precedence table
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.MODULO_ASSIGN:   ASSIGN,
+	token.AND:             LOGICAL_AND,
+	token.OR:              LOGICAL_OR,
+	token.PIPE:            PIPE,
+	token.BIT_OR:          BITWISE_OR,
+	token.BIT_XOR:         BITWISE_XOR,
+	token.BIT_AND:         BITWISE_AND,
+	token.DOTDOT:          RANGE,
+	token.DOTDOT_EQ:       RANGE,
+	token.SHL:             SHIFT,
+	token.SHR:             SHIFT,
+	token.MODULO:          PRODUCT,
+	token.POWER:           POWER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
+	token.DOT:             CALL,
 }
 
+// Infix operators that group to the right, so 2 ** 3 ** 2 is 2 ** (3 ** 2)
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER: true,
+}
+
+// Diagnostic codes reported by the parser
+const (
+	ErrUnexpectedToken  = "P0001" // a specific token was expected but another one was found
+	ErrNoPrefixParseFn  = "P0002" // the token cannot start an expression
+	ErrInvalidInteger   = "P0003" // an integer literal could not be converted to a value
+	ErrInvalidFloat     = "P0004" // a float literal is out of the range of a 64-bit float
+	ErrInvalidAssign    = "P0005" // the left side of = is not something that can be assigned to
+	ErrOutsideLoop      = "P0006" // break or continue outside of a loop body
+	ErrInvalidPattern   = "P0007" // a match arm or let does not start with a valid pattern
+	ErrDuplicateBinding = "P0008" // a pattern or parameter list binds the same name twice
+	ErrInvalidParameter = "P0009" // a function parameter is malformed or out of order
+	ErrInvalidArgument  = "P0010" // a keyword argument is repeated or followed by a positional one
+	ErrInvalidPipe      = "P0011" // the right side of |> is not a call
+)
+
+// Warning codes reported by the parser
+const (
+	WarnNonExhaustive  = "W0001" // no match arm is guaranteed to match
+	WarnUnreachableArm = "W0002" // a match arm can never be reached
+)
+
 // Parser definition
 type Parser struct {
 	l              *lexer.Lexer
 	curToken       *token.Token
 	peekToken      *token.Token
-	errors         []string
+	prevToken      *token.Token // the token before curToken, used by backup
+	pushedBack     *token.Token // a token returned to the stream by backup
+	depth          int          // number of unclosed { up to and including curToken
+	errors         []diagnostic.Diagnostic
+	warnings       []diagnostic.Diagnostic
+	panicking      bool // an error was reported and the parser has not resynchronized yet
+	loopDepth      int  // number of loops around curToken within the current function
+	noArrow        bool // name => ... is not an arrow function here, as in a match guard
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -56,7 +127,7 @@ type Parser struct {
 
 // New creates and initializes a new Parser with the given lexer.
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l, errors: []diagnostic.Diagnostic{}}
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -69,10 +140,18 @@ func New(l *lexer.Lexer) *Parser {
 	// Register prefix parse functions
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.RATIONAL, p.parseRationalLiteral)
+	p.registerPrefix(token.DECIMAL, p.parseDecimalLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.BIT_NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.WHILE, p.parseWhileExpression)
+	p.registerPrefix(token.FOR, p.parseForExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 
 	// Register infix parse functions
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -83,7 +162,29 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.MODULO, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.BIT_AND, p.parseInfixExpression)
+	p.registerInfix(token.BIT_OR, p.parseInfixExpression)
+	p.registerInfix(token.BIT_XOR, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MODULO_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.DOTDOT, p.parseRangeExpression)
+	p.registerInfix(token.DOTDOT_EQ, p.parseRangeExpression)
+	p.registerInfix(token.AND, p.parseLogicalExpression)
+	p.registerInfix(token.OR, p.parseLogicalExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	// Register other prefix parse functions as needed
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -92,6 +193,14 @@ func New(l *lexer.Lexer) *Parser {
 	// Register grouping parse functions
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 
+	// Register collection literal parse functions
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	// A { only reaches the prefix table in expression position; blocks are
+	// parsed directly by the constructs that own them (if, fn).
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
+
 	return p
 }
 
@@ -111,8 +220,36 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 
 // nextToken advances the parser to the next token.
 func (p *Parser) nextToken() {
+	p.prevToken = p.curToken
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	if p.pushedBack != nil {
+		p.peekToken = p.pushedBack
+		p.pushedBack = nil
+	} else {
+		p.peekToken = p.l.NextToken()
+	}
+	if p.curToken != nil {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			p.depth++
+		case token.RBRACE:
+			p.depth--
+		}
+	}
+}
+
+// backup undoes the last nextToken. Only one token can be backed up.
+func (p *Parser) backup() {
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth--
+	case token.RBRACE:
+		p.depth++
+	}
+	p.pushedBack = p.peekToken
+	p.peekToken = p.curToken
+	p.curToken = p.prevToken
+	p.prevToken = nil
 }
 
 // curTokenIs checks if the current token is of the specified type.
@@ -140,22 +277,116 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 // ERROR HANDLING
 // ============================
 
-// Errors returns the parser errors.
-func (p *Parser) Errors() []string {
-	return p.errors
+// Errors returns the lexer and parser errors in source order.
+func (p *Parser) Errors() []diagnostic.Diagnostic {
+	errors := make([]diagnostic.Diagnostic, 0, len(p.l.Errors())+len(p.errors))
+	errors = append(errors, p.l.Errors()...)
+	errors = append(errors, p.errors...)
+	sort.SliceStable(errors, func(i, j int) bool {
+		return errors[i].Span.Start.Offset < errors[j].Span.Start.Offset
+	})
+	return errors
+}
+
+// Warnings returns the parser warnings in source order. They do not stop
+// the program from running.
+func (p *Parser) Warnings() []diagnostic.Diagnostic {
+	warnings := append([]diagnostic.Diagnostic{}, p.warnings...)
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Span.Start.Offset < warnings[j].Span.Start.Offset
+	})
+	return warnings
+}
+
+// errorAt records an error diagnostic covering tok and puts the parser in
+// panic mode. Errors reported while panicking are dropped, since they are
+// almost always a consequence of the first one.
+func (p *Parser) errorAt(tok *token.Token, code string, notes []string, format string, args ...interface{}) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+	p.errors = append(p.errors, diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span:     diagnostic.SpanOf(*tok),
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Notes:    notes,
+	})
+}
+
+// nodeError adds an error spanning node. Unlike errorAt it does not enter
+// panic mode: node was parsed completely, so the parser is still in sync.
+func (p *Parser) nodeError(node ast.Node, code string, notes []string, format string, args ...interface{}) {
+	if p.panicking {
+		return
+	}
+	p.errors = append(p.errors, diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Span:     diagnostic.Span{Start: node.Pos(), End: node.End()},
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Notes:    notes,
+	})
+}
+
+// nodeWarning adds a warning spanning node.
+func (p *Parser) nodeWarning(node ast.Node, code string, notes []string, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, diagnostic.Diagnostic{
+		Severity: diagnostic.Warning,
+		Span:     diagnostic.Span{Start: node.Pos(), End: node.End()},
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Notes:    notes,
+	})
 }
 
 // peekError adds an error when the next token isn't what was expected.
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
-		t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.peekToken, ErrUnexpectedToken, nil,
+		"expected next token to be %s, got %s instead", t, p.peekToken.Type)
 }
 
 // noPrefixParseFnError adds an error when no prefix parse function exists.
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.curToken, ErrNoPrefixParseFn, nil,
+		"no prefix parse function for %s found", t)
+}
+
+// synchronize leaves panic mode by skipping to the end of the statement that
+// started at start, where startDepth was the brace depth before that statement.
+// It stops after a ; or before a statement keyword or the } closing the
+// enclosing block, ignoring any of those nested inside braces the statement opened.
+func (p *Parser) synchronize(start *token.Token, startDepth int) {
+	p.panicking = false
+	if p.curToken != start && p.curTokenIs(token.RBRACE) && p.depth < startDepth {
+		// The error was found at the } of the enclosing block, give it back so
+		// that the block still sees its end.
+		p.backup()
+		return
+	}
+	for !p.peekTokenIs(token.EOF) {
+		if p.depth == startDepth {
+			if p.curTokenIs(token.SEMICOLON) {
+				return
+			}
+			switch p.peekToken.Type {
+			case token.SEMICOLON:
+				p.nextToken()
+				return
+			case token.RBRACE, token.LET, token.RETURN,
+				token.WHILE, token.FOR, token.BREAK, token.CONTINUE:
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// badExpression returns a placeholder for an expression that started at from
+// and could not be parsed.
+func (p *Parser) badExpression(from *token.Token) ast.Expression {
+	return &ast.BadExpression{From: *from, To: *p.curToken}
 }
 
 // ============================
@@ -202,33 +433,81 @@ func (p *Parser) ParseProgram() *ast.Program {
 // STATEMENT PARSING
 // ============================
 
-// parseStatement dispatches to the appropriate statement parser.
+// parseStatement parses one statement, recovering from any error inside it.
+// A statement too broken to build is replaced by an ast.BadStatement.
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken
+	startDepth := p.depth
+	switch start.Type {
+	case token.LBRACE:
+		startDepth--
+	case token.RBRACE:
+		startDepth++
+	}
+
+	stmt := p.parseStatementByKind()
+	if !p.panicking {
+		return stmt
+	}
+	p.synchronize(start, startDepth)
+	if stmt == nil {
+		return &ast.BadStatement{From: *start, To: *p.curToken}
+	}
+	return stmt
+}
+
+// parseStatementByKind dispatches to the appropriate statement parser.
+// It returns nil when the statement could not be built.
+func (p *Parser) parseStatementByKind() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		if stmt := p.parseLetStatement(); stmt != nil {
+			return stmt
+		}
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
+	case token.FUNCTION:
+		if !p.peekTokenIs(token.IDENT) {
+			return p.parseExpressionStatement()
+		}
+		if stmt := p.parseFunctionDeclaration(); stmt != nil {
+			return stmt
+		}
 	default:
 		return p.parseExpressionStatement()
 	}
+	return nil
 }
 
-// parseLetStatement parses let statements.
+// parseLetStatement parses let statements, including destructuring ones
+// such as let [a, b] = xs; and let {name, age: years} = person;.
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: *p.curToken}
-	if !p.expectPeek(token.IDENT) {
+	stmt := &ast.LetStatement{Token: *p.curToken, Doc: ast.NewCommentGroup(p.curToken.Doc)}
+	switch p.peekToken.Type {
+	case token.IDENT, token.LBRACKET, token.LBRACE:
+		p.nextToken()
+	default:
+		p.peekError(token.IDENT)
+		return nil
+	}
+	stmt.Name = p.parsePattern()
+	if stmt.Name == nil {
 		return nil
 	}
-	stmt.Name = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+	p.checkPatternBindings(stmt.Name, true)
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		if binding, ok := stmt.Name.(*ast.BindingPattern); ok {
+			fn.Name = binding.Name.Value
+		}
 	}
+	p.skipOptionalSemicolon()
 	return stmt
 }
 
@@ -237,20 +516,41 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: *p.curToken}
 	p.nextToken()
 	stmt.ReturnValue = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	p.skipOptionalSemicolon()
+	return stmt
+}
+
+// parseLoopControlStatement parses break and continue, which are only
+// allowed inside the body of a loop in the same function.
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	var stmt ast.Statement
+	if p.curTokenIs(token.BREAK) {
+		stmt = &ast.BreakStatement{Token: *p.curToken}
+	} else {
+		stmt = &ast.ContinueStatement{Token: *p.curToken}
+	}
+	if p.loopDepth == 0 {
+		p.nodeError(stmt, ErrOutsideLoop, nil, "%s outside of a loop", p.curToken.Literal)
 	}
+	p.skipOptionalSemicolon()
 	return stmt
 }
 
+// skipOptionalSemicolon steps onto the semicolon ending a statement, if any.
+// While panicking the semicolon is left for synchronize, because the error
+// may have been found on a } that belongs to an enclosing block.
+func (p *Parser) skipOptionalSemicolon() {
+	if !p.panicking && p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+}
+
 // parseExpressionStatement parses expression statements.
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: *p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
+	p.skipOptionalSemicolon()
 
 	return stmt
 }
@@ -264,12 +564,12 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
+		return p.badExpression(p.curToken)
 	}
 
 	leftExp := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	for !p.panicking && !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -282,23 +582,116 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	return leftExp
 }
 
-// parseGroupedExpression parses expressions within parentheses.
+// parseGroupedExpression parses expressions within parentheses, and the
+// parameter lists of arrow functions. The parenthesized list is parsed once,
+// as expressions, and turned into parameters when => follows it.
 func (p *Parser) parseGroupedExpression() ast.Expression {
-	p.nextToken() // consume '('
-	expression := p.parseExpression(LOWEST)
-	if !p.expectPeek(token.RPAREN) {
-		p.peekError(token.RPAREN)
-		return nil
+	start := p.curToken
+	items, comma, ok := p.parseGroupItems()
+	if !ok {
+		return p.badExpression(start)
 	}
-	return expression
+	if !p.noArrow && p.peekTokenIs(token.ARROW) {
+		return p.parseArrowFunction(start, p.groupParameters(items))
+	}
+	switch {
+	case len(items) == 0:
+		p.noPrefixParseFnError(token.RPAREN)
+	case comma != nil:
+		p.errorAt(comma, ErrUnexpectedToken, nil,
+			"expected next token to be %s, got %s instead", token.RPAREN, token.COMMA)
+	case items[0].rest:
+		p.errorAt(&items[0].start, ErrNoPrefixParseFn, nil,
+			"no prefix parse function for %s found", token.ELLIPSIS)
+	default:
+		return items[0].expr
+	}
+	return p.badExpression(start)
+}
+
+// groupItem is one comma separated element of a parenthesized list.
+type groupItem struct {
+	start token.Token // the first token of the element
+	expr  ast.Expression
+	rest  bool // the element is ...name, which only a parameter list allows
+}
+
+// parseGroupItems parses the comma separated elements between the ( at
+// curToken and its ), and returns them with the first comma, if any.
+func (p *Parser) parseGroupItems() ([]groupItem, *token.Token, bool) {
+	// within the parentheses name => ... is an arrow function again
+	noArrow := p.noArrow
+	p.noArrow = false
+	defer func() { p.noArrow = noArrow }()
+
+	items := []groupItem{}
+	var comma *token.Token
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		item := groupItem{start: *p.curToken}
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil, nil, false
+			}
+			item.rest = true
+			item.expr = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+		} else {
+			item.expr = p.parseExpression(LOWEST)
+		}
+		items = append(items, item)
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
+		if !p.peekTokenIs(token.COMMA) {
+			p.peekError(token.RPAREN)
+			return nil, nil, false
+		}
+		p.nextToken()
+		if comma == nil {
+			comma = p.curToken
+		}
+	}
+	p.nextToken()
+	return items, comma, true
+}
+
+// groupParameters turns the elements of a parenthesized list followed by
+// => into parameters. Elements have to be a name, name = default or
+// ...name; any other element is reported and left out.
+func (p *Parser) groupParameters(items []groupItem) []*ast.Parameter {
+	params := []*ast.Parameter{}
+	for _, item := range items {
+		param := &ast.Parameter{Token: item.start, Rest: item.rest}
+		switch expr := item.expr.(type) {
+		case *ast.Identifier:
+			param.Name = expr
+		case *ast.AssignExpression:
+			if name, ok := expr.Target.(*ast.Identifier); ok && expr.Operator == "=" {
+				param.Name, param.Default = name, expr.Value
+			}
+		}
+		// a name in parentheses of its own, as in ((x)) => x, is not a parameter
+		if param.Name == nil || !item.rest && param.Name.Token.Pos != item.start.Pos {
+			p.nodeError(item.expr, ErrInvalidParameter, nil,
+				"expected a parameter name, got %s", item.expr)
+			continue
+		}
+		params = append(params, param)
+	}
+	p.checkParameters(params)
+	return params
 }
 
 // ============================
 // PREFIX EXPRESSION PARSERS
 // ============================
 
-// parseIdentifier parses identifiers.
+// parseIdentifier parses identifiers, and arrow functions like x => x * 2.
 func (p *Parser) parseIdentifier() ast.Expression {
+	if !p.noArrow && p.peekTokenIs(token.ARROW) {
+		name := &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+		return p.parseArrowFunction(p.curToken, []*ast.Parameter{{Token: *p.curToken, Name: name}})
+	}
 	return &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
 }
 
@@ -307,16 +700,68 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: *p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		// too large for an int64, so the literal becomes a big integer
+		if n, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			lit.Big = n
+			return lit
+		}
+	}
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		p.errorAt(p.curToken, ErrInvalidInteger,
+			[]string{"integer literals must fit in a signed 64-bit integer"},
+			"could not parse %q as integer", p.curToken.Literal)
+		return p.badExpression(p.curToken)
+	}
+
+	lit.Value = value
+	return lit
+}
+
+// parseFloatLiteral parses floating point literals.
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: *p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.errorAt(p.curToken, ErrInvalidFloat,
+			[]string{"float literals must fit in a 64-bit IEEE 754 float"},
+			"could not parse %q as float", p.curToken.Literal)
+		return p.badExpression(p.curToken)
 	}
 
 	lit.Value = value
 	return lit
 }
 
+// parseRationalLiteral parses rational literals such as 3r. The lexer has
+// already checked the digits, so they always convert.
+func (p *Parser) parseRationalLiteral() ast.Expression {
+	digits := strings.TrimSuffix(p.curToken.Literal, "r")
+	n, _ := new(big.Int).SetString(digits, 0)
+	return &ast.RationalLiteral{Token: *p.curToken, Value: new(big.Rat).SetInt(n)}
+}
+
+// parseDecimalLiteral parses fixed-point decimal literals such as 12.50d.
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	digits := strings.ReplaceAll(strings.TrimSuffix(p.curToken.Literal, "d"), "_", "")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	unscaled, _ := new(big.Int).SetString(whole+fraction, 10)
+	return &ast.DecimalLiteral{Token: *p.curToken, Unscaled: unscaled, Scale: len(fraction)}
+}
+
+// parseIllegal handles a token the lexer could not make sense of. The lexer
+// has already reported it, so the parser only has to start recovering.
+func (p *Parser) parseIllegal() ast.Expression {
+	p.panicking = true
+	return p.badExpression(p.curToken)
+}
+
+// parseStringLiteral parses string literals.
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: *p.curToken, Value: p.curToken.Literal}
+}
+
 // parsePrefixExpression parses prefix expressions like -x or !x.
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
@@ -348,33 +793,114 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if rightAssociative[p.curToken.Type] {
+		// parsing the right side one level lower lets it take in
+		// another operator of the same precedence
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+// parseRangeExpression parses low..high and low..=high.
+func (p *Parser) parseRangeExpression(low ast.Expression) ast.Expression {
+	expression := &ast.RangeExpression{
+		Token:     *p.curToken,
+		Low:       low,
+		Inclusive: p.curTokenIs(token.DOTDOT_EQ),
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.High = p.parseExpression(precedence)
+
+	return expression
+}
+
+// parsePipeExpression parses left |> f(args) and left |> h.f(args). The
+// right side has to be a call or a method call, which gets left as its
+// first argument. It ends with the call, so operators after it apply to the
+// whole pipe: xs |> len() == 3 is (xs |> len()) == 3.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	expression := &ast.PipeExpression{Token: *p.curToken, Left: left}
+	p.nextToken()
+	right := p.parseExpression(POWER)
+	switch right.(type) {
+	case *ast.CallExpression, *ast.MethodCallExpression:
+	default:
+		p.nodeError(right, ErrInvalidPipe,
+			[]string{"write x |> f() to call f(x)"},
+			"right side of |> must be a call, got %s", right)
+		return p.badExpression(&expression.Token)
+	}
+	expression.Call = right
+	return expression
+}
+
+// parseLogicalExpression parses a && b and a || b. They get their own node
+// because, unlike infix operators, they do not always evaluate b.
+func (p *Parser) parseLogicalExpression(left ast.Expression) ast.Expression {
+	expression := &ast.LogicalExpression{
+		Token:    *p.curToken,
+		Operator: p.curToken.Literal,
+		Left:     left,
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
+// parseAssignExpression parses x = value and the compound forms such as
+// x += value. Assignment is right-associative, so a = b = 1 assigns 1 to
+// both.
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    *p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+	default:
+		p.nodeError(target, ErrInvalidAssign,
+			[]string{"only variables, index expressions like xs[0] and members like p.x can be assigned to"},
+			"cannot assign to %s", target.String())
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGN - 1)
+
+	return expression
+}
+
 // ============================
 // IF,ELSE EXPRESSION PARSERS
 // ============================
 func (p *Parser) parseIfExpression() ast.Expression {
+	start := p.curToken
 	expression := &ast.IfExpression{Token: *p.curToken}
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(start)
 	}
 	p.nextToken()
 	expression.Condition = p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpression(start)
 	}
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(start)
 	}
 	expression.Consequence = p.parseBlockStatement()
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 		if !p.expectPeek(token.LBRACE) {
-			return nil
+			return p.badExpression(start)
 		}
 		expression.Alternative = p.parseBlockStatement()
 	}
@@ -391,45 +917,272 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	if p.curTokenIs(token.EOF) {
+		p.errorAt(p.curToken, ErrUnexpectedToken, nil,
+			"expected %s to close the block, got %s instead", token.RBRACE, token.EOF)
+	}
+	block.Rbrace = *p.curToken
 	return block
 }
 
+// ============================
+// LOOP EXPRESSION PARSERS
+// ============================
+
+// parseWhileExpression parses while (condition) { body }.
+func (p *Parser) parseWhileExpression() ast.Expression {
+	start := p.curToken
+	expression := &ast.WhileExpression{Token: *p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return p.badExpression(start)
+	}
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return p.badExpression(start)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return p.badExpression(start)
+	}
+	expression.Body = p.parseLoopBody()
+	return expression
+}
+
+// parseForExpression parses for (init; condition; update) { body }.
+func (p *Parser) parseForExpression() ast.Expression {
+	start := p.curToken
+	expression := &ast.ForExpression{Token: *p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return p.badExpression(start)
+	}
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		if p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA) {
+			return p.parseForInExpression(start)
+		}
+		p.backup()
+	}
+
+	p.nextToken()
+	switch p.curToken.Type {
+	case token.SEMICOLON:
+	case token.LET:
+		init := p.parseLetStatement()
+		if init == nil {
+			return p.badExpression(start)
+		}
+		expression.Init = init
+		// parseLetStatement steps onto the ; when it is there
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return p.badExpression(start)
+		}
+	default:
+		init := &ast.ExpressionStatement{Token: *p.curToken}
+		init.Expression = p.parseExpression(LOWEST)
+		expression.Init = init
+		if !p.expectPeek(token.SEMICOLON) {
+			return p.badExpression(start)
+		}
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		expression.Condition = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.SEMICOLON) {
+		return p.badExpression(start)
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		expression.Update = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return p.badExpression(start)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return p.badExpression(start)
+	}
+	expression.Body = p.parseLoopBody()
+	return expression
+}
+
+// parseForInExpression parses the rest of for (value in iterable) { body }
+// or for (key, value in iterable) { body }, from the first binding on.
+func (p *Parser) parseForInExpression(start *token.Token) ast.Expression {
+	expression := &ast.ForInExpression{Token: *start}
+	expression.Value = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return p.badExpression(start)
+		}
+		expression.Key = expression.Value
+		expression.Value = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+	}
+	if !p.expectPeek(token.IN) {
+		return p.badExpression(start)
+	}
+	p.nextToken()
+	expression.Iterable = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return p.badExpression(start)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return p.badExpression(start)
+	}
+	expression.Body = p.parseLoopBody()
+	return expression
+}
+
+// parseLoopBody parses the block of a loop, in which break and continue
+// are allowed.
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loopDepth++
+	body := p.parseBlockStatement()
+	p.loopDepth--
+	return body
+}
+
 // ============================
 // FUNCTION EXPRESSION PARSERS
 // ============================
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
+	start := p.curToken
 	lit := &ast.FunctionLiteral{Token: *p.curToken}
-	if !p.expectPeek(token.LPAREN) {
+	if !p.parseFunctionRest(lit) {
+		return p.badExpression(start)
+	}
+	return lit
+}
+
+// parseArrowFunction parses the => and the body of an arrow function whose
+// parameters have already been parsed, from the token before the => on.
+// start is the ( or the name the function starts with. The body is an
+// expression, or a block when it starts with {.
+func (p *Parser) parseArrowFunction(start *token.Token, params []*ast.Parameter) ast.Expression {
+	lit := &ast.FunctionLiteral{Token: *start, Arrow: true, Parameters: params}
+	p.nextToken() // the =>, checked by the caller
+
+	// break and continue cannot reach loops outside of the function
+	loopDepth, noArrow := p.loopDepth, p.noArrow
+	p.loopDepth, p.noArrow = 0, false
+	defer func() { p.loopDepth, p.noArrow = loopDepth, noArrow }()
+
+	p.nextToken()
+	if p.curTokenIs(token.LBRACE) {
+		lit.Body = p.parseBlockStatement()
+		return lit
+	}
+	body := &ast.ExpressionStatement{Token: *p.curToken}
+	body.Expression = p.parseExpression(LOWEST)
+	lit.Body = &ast.BlockStatement{Token: body.Token, Statements: []ast.Statement{body}}
+	return lit
+}
+
+// parseFunctionDeclaration parses fn name(params) { body }.
+func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
+	decl := &ast.FunctionDeclaration{Token: *p.curToken, Doc: ast.NewCommentGroup(p.curToken.Doc)}
+	p.nextToken()
+	decl.Name = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+	decl.Function = &ast.FunctionLiteral{Token: decl.Token, Name: decl.Name.Value}
+	if !p.parseFunctionRest(decl.Function) {
 		return nil
 	}
+	p.skipOptionalSemicolon()
+	return decl
+}
+
+// parseFunctionRest parses the parameters and body of lit, from the token
+// before the ( on. It reports whether they could be parsed.
+func (p *Parser) parseFunctionRest(lit *ast.FunctionLiteral) bool {
+	if !p.expectPeek(token.LPAREN) {
+		return false
+	}
 	lit.Parameters = p.parseFunctionParameters()
+	if lit.Parameters == nil {
+		return false
+	}
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return false
 	}
+	// break and continue cannot reach loops outside of the function
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
 	lit.Body = p.parseBlockStatement()
-	return lit
+	p.loopDepth = loopDepth
+	return true
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
-	if p.peekTokenIs(token.RPAREN) {
+// parseFunctionParameters parses the parameter list of a function literal:
+// plain names first, then names with defaults, then at most one ...rest.
+func (p *Parser) parseFunctionParameters() []*ast.Parameter {
+	params := []*ast.Parameter{}
+	for !p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		param := p.parseParameter()
+		if param == nil {
+			return nil
+		}
+		params = append(params, param)
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
 	}
 	p.nextToken()
-	ident := &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+	p.checkParameters(params)
+	return params
+}
+
+// parseParameter parses name, name = default or ...name.
+func (p *Parser) parseParameter() *ast.Parameter {
+	param := &ast.Parameter{Token: *p.curToken}
+	if p.curTokenIs(token.ELLIPSIS) {
+		param.Rest = true
 		p.nextToken()
-		ident := &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
 	}
-	if !p.expectPeek(token.RPAREN) {
+	if !p.curTokenIs(token.IDENT) {
+		p.errorAt(p.curToken, ErrInvalidParameter, nil,
+			"expected a parameter name, got %s", p.curToken.Type)
 		return nil
 	}
-	return identifiers
+	param.Name = &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+	if !param.Rest && p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(LOWEST)
+	}
+	return param
+}
+
+// checkParameters reports parameters that have the same name as an earlier
+// one or that cannot follow the one before them.
+func (p *Parser) checkParameters(params []*ast.Parameter) {
+	seen := map[string]bool{}
+	for i, param := range params {
+		if seen[param.Name.Value] {
+			p.nodeError(param.Name, ErrDuplicateBinding, nil,
+				"duplicate parameter %s", param.Name.Value)
+		}
+		seen[param.Name.Value] = true
+		if i > 0 {
+			p.checkParameterOrder(params[i-1], param)
+		}
+	}
+}
+
+// checkParameterOrder reports param when it cannot follow prev.
+func (p *Parser) checkParameterOrder(prev, param *ast.Parameter) {
+	switch {
+	case prev.Rest:
+		p.nodeError(prev, ErrInvalidParameter, nil,
+			"rest parameter %s must be the last parameter", prev)
+	case prev.Default != nil && param.Default == nil && !param.Rest:
+		p.nodeError(param, ErrInvalidParameter,
+			[]string{"parameters with defaults have to come after the ones without"},
+			"parameter %s needs a default value", param)
+	}
 }
 
 // ============================
@@ -439,23 +1192,154 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: *p.curToken, Function: function}
 	exp.Arguments = p.parseCallArguments()
+	exp.Rparen = *p.curToken
 	return exp
 }
+
+// parseCallArguments parses the arguments of a call, allowing a trailing
+// comma. Keyword arguments such as b: 5 come after the positional ones.
 func (p *Parser) parseCallArguments() []ast.Expression {
+	// arguments are delimited, so name => ... is an arrow function in them
+	noArrow := p.noArrow
+	p.noArrow = false
+	defer func() { p.noArrow = noArrow }()
+
 	args := []ast.Expression{}
-	if p.peekTokenIs(token.RPAREN) {
+	keywords := map[string]bool{}
+	for !p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			arg := &ast.KeywordArgument{Name: &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}}
+			p.nextToken()
+			p.nextToken()
+			arg.Value = p.parseExpression(LOWEST)
+			if keywords[arg.Name.Value] {
+				p.nodeError(arg, ErrInvalidArgument, nil,
+					"keyword argument %s given more than once", arg.Name.Value)
+			}
+			keywords[arg.Name.Value] = true
+			args = append(args, arg)
+		} else {
+			arg := p.parseExpression(LOWEST)
+			if len(keywords) > 0 {
+				p.nodeError(arg, ErrInvalidArgument, nil,
+					"positional argument %s cannot follow keyword arguments", arg)
+			}
+			args = append(args, arg)
+		}
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
 	}
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	return args
+}
+
+// parseExpressionList parses comma separated expressions up to the end token,
+// allowing a trailing comma.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+	p.nextToken()
+	list = append(list, p.parseExpression(LOWEST))
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		if p.peekTokenIs(end) {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
-	if !p.expectPeek(token.RPAREN) {
+	if !p.expectPeek(end) {
 		return nil
 	}
-	return args
+	return list
+}
+
+// ============================
+// ARRAY, HASH AND INDEX EXPRESSION PARSERS
+// ============================
+
+// parseArrayLiteral parses array literals like [1, 2 * 3].
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: *p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.Rbracket = *p.curToken
+	return array
+}
+
+// parseHashLiteral parses hash literals like {"one": 1, 2: true}, keeping the
+// pairs in source order.
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: *p.curToken, Pairs: []ast.HashPair{}}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if !p.expectPeek(token.COLON) {
+			return p.badExpression(&hash.Token)
+		}
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return p.badExpression(&hash.Token)
+		}
+	}
+	p.nextToken()
+	hash.Rbrace = *p.curToken
+	return hash
+}
+
+// parseIndexExpression parses index expressions like array[index].
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: *p.curToken, Left: left}
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(exp.Token, left, nil)
+	}
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(exp.Token, left, exp.Index)
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return p.badExpression(&exp.Token)
+	}
+	exp.Rbracket = *p.curToken
+	return exp
+}
+
+// parseMemberExpression parses object.name and object.name(args).
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	dot := p.curToken
+	if !p.expectPeek(token.IDENT) {
+		return p.badExpression(dot)
+	}
+	name := &ast.Identifier{Token: *p.curToken, Value: p.curToken.Literal}
+	if !p.peekTokenIs(token.LPAREN) {
+		return &ast.MemberExpression{Token: *dot, Object: object, Property: name}
+	}
+	p.nextToken()
+	exp := &ast.MethodCallExpression{Token: *dot, Object: object, Method: name}
+	exp.Arguments = p.parseCallArguments()
+	exp.Rparen = *p.curToken
+	return exp
+}
+
+// parseSliceExpression parses the rest of left[low:high] from the token
+// before the colon on. Both bounds are optional, so low may be nil.
+func (p *Parser) parseSliceExpression(lbracket token.Token, left, low ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: lbracket, Left: left, Low: low}
+	p.nextToken()
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.High = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return p.badExpression(&exp.Token)
+	}
+	exp.Rbracket = *p.curToken
+	return exp
 }
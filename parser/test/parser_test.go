@@ -2,9 +2,12 @@ package test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TusharAbhinav/monkey/ast"
+	"github.com/TusharAbhinav/monkey/diagnostic"
 	lexer "github.com/TusharAbhinav/monkey/lexer"
 	parser "github.com/TusharAbhinav/monkey/parser"
 )
@@ -40,6 +43,71 @@ let foobar = 838383;
 		}
 	}
 }
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input       string
+		patternType string
+		expected    string
+	}{
+		{"let [a, b] = xs;", "*ast.ArrayPattern", "let [a, b] = xs;"},
+		{"let [first, ...rest] = xs;", "*ast.ArrayPattern", "let [first, ...rest] = xs;"},
+		{"let [[a, b], _] = xs;", "*ast.ArrayPattern", "let [[a, b], _] = xs;"},
+		{"let {name, age: years} = person;", "*ast.HashPattern", "let {name, age: years} = person;"},
+		{`let {"k": [x, y], 1: z} = h;`, "*ast.HashPattern", `let {"k": [x, y], 1: z} = h;`},
+		{"let _ = f();", "*ast.WildcardPattern", "let _ = f();"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("%q: stmt not *ast.LetStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if fmt.Sprintf("%T", stmt.Name) != tt.patternType {
+			t.Errorf("%q: stmt.Name not %s. got=%T", tt.input, tt.patternType, stmt.Name)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestPatternBindingErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedCode    string
+		expectedMessage string
+		expectedStart   int
+	}{
+		{"let [a, a] = xs;", parser.ErrDuplicateBinding, "a is bound more than once in this pattern", 8},
+		{"let [a, ...a] = xs;", parser.ErrDuplicateBinding, "a is bound more than once in this pattern", 11},
+		{"let {a, b: a} = h;", parser.ErrDuplicateBinding, "a is bound more than once in this pattern", 11},
+		{"match (v) { [x, {k: x}] => x, _ => 0 }", parser.ErrDuplicateBinding, "x is bound more than once in this pattern", 20},
+		{"let [1, a] = xs;", parser.ErrInvalidPattern, "cannot use literal 1 in a let pattern", 5},
+		{"let 5 = x;", parser.ErrUnexpectedToken, "expected next token to be IDENT, got INT instead", 4},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Code != tt.expectedCode {
+			t.Errorf("%q: wrong code. expected=%s, got=%s", tt.input, tt.expectedCode, errors[0].Code)
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, errors[0].Message)
+		}
+		if errors[0].Span.Start.Offset != tt.expectedStart {
+			t.Errorf("%q: wrong start. expected=%d, got=%d", tt.input, tt.expectedStart, errors[0].Span.Start.Offset)
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
@@ -87,8 +155,13 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 		t.Errorf("s not *ast.LetStatement. got=%T", s)
 		return false
 	}
-	if letStmt.Name.Value != name {
-		t.Errorf("letStmt.Name.Value not '%s'. got=%s", name, letStmt.Name.Value)
+	binding, ok := letStmt.Name.(*ast.BindingPattern)
+	if !ok {
+		t.Errorf("letStmt.Name not *ast.BindingPattern. got=%T", letStmt.Name)
+		return false
+	}
+	if binding.Name.Value != name {
+		t.Errorf("letStmt.Name.Value not '%s'. got=%s", name, binding.Name.Value)
 		return false
 	}
 	if letStmt.Name.TokenLiteral() != name {
@@ -151,6 +224,125 @@ func TestIntegerLiteralExpression(t *testing.T) {
 			literal.TokenLiteral())
 	}
 }
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+		if program.String() != tt.input {
+			t.Errorf("literal spelling not preserved. expected=%q, got=%q", tt.input, program.String())
+		}
+	}
+}
+
+func TestBigIntegerLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775808", "9223372036854775808"},
+		{"0xFFFF_FFFF_FFFF_FFFF_FFFF", "1208925819614629174706175"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Big == nil || literal.Big.String() != tt.expected {
+			t.Errorf("literal.Big not %s. got=%v", tt.expected, literal.Big)
+		}
+	}
+}
+
+func TestExactNumberLiterals(t *testing.T) {
+	p := parser.New(lexer.New("1/3r; 12.50d;"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program has wrong number of statements. got=%d", len(program.Statements))
+	}
+
+	division := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+	rational, ok := division.Right.(*ast.RationalLiteral)
+	if !ok {
+		t.Fatalf("right side is not *ast.RationalLiteral. got=%T", division.Right)
+	}
+	if rational.Value.RatString() != "3" {
+		t.Errorf("rational.Value not 3. got=%s", rational.Value.RatString())
+	}
+
+	decimal, ok := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.DecimalLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.DecimalLiteral. got=%T", program.Statements[1])
+	}
+	if decimal.Unscaled.String() != "1250" || decimal.Scale != 2 {
+		t.Errorf("decimal wrong. expected 1250 scale 2, got=%s scale %d", decimal.Unscaled, decimal.Scale)
+	}
+	if decimal.String() != "12.50d" {
+		t.Errorf("decimal.String() wrong. got=%q", decimal.String())
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.25;", 3.25},
+		{"1e-9;", 1e-9},
+		{"2.5E+3;", 2500},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
+func TestFloatLiteralOutOfRange(t *testing.T) {
+	p := parser.New(lexer.New("1e999;"))
+	p.ParseProgram()
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("wrong number of errors. expected=1, got=%d (%v)", len(errors), errors)
+	}
+	if errors[0].Code != parser.ErrInvalidFloat {
+		t.Errorf("error code wrong. expected=%s, got=%s", parser.ErrInvalidFloat, errors[0].Code)
+	}
+}
+
 func TestParsingBooleanExpression(t *testing.T) {
 	input := "true;"
 	l := lexer.New(input)
@@ -325,6 +517,66 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"!(true == true)",
 			"(!(true == true))",
 		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"a[i][j]",
+			"((a[i])[j])",
+		},
+		{
+			"f(x)[0]",
+			"(f(x)[0])",
+		},
+		{"a <= b == b >= a", "((a <= b) == (b >= a))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"!a && b", "((!a) && b)"},
+		{"a * b % c", "((a * b) % c)"},
+		{"a + b % c", "(a + (b % c))"},
+		{"2 ** 3 ** 2", "(2 ** (3 ** 2))"},
+		{"-2 ** 2", "(-(2 ** 2))"},
+		{"a * b ** c", "(a * (b ** c))"},
+		{"2 ** -1", "(2 ** (-1))"},
+		{"a << 1 + b", "(a << (1 + b))"},
+		{"a < b << 1", "(a < (b << 1))"},
+		{"a & b == c", "(a & (b == c))"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a | b && c", "((a | b) && c)"},
+		{"~a & b", "((~a) & b)"},
+		{"0..n + 1", "(0..(n + 1))"},
+		{"a < 1..=b", "(a < (1..=b))"},
+		{"1 << 2..3", "((1 << 2)..3)"},
+		{"xs[1:3]", "(xs[1:3])"},
+		{"s[:-1]", "(s[:(-1)])"},
+		{"xs[i + 1:]", "(xs[(i + 1):])"},
+		{"xs[:]", "(xs[:])"},
+		{"xs[1:2][0]", "((xs[1:2])[0])"},
+		{"xs |> f() |> g(1)", "((xs |> f()) |> g(1))"},
+		{"a && xs |> any() || b", "((a && (xs |> any())) || b)"},
+		{"x + 1 |> f()", "((x + 1) |> f())"},
+		{"xs |> len() == 3", "((xs |> len()) == 3)"},
+		{"xs |> len() > 2 && ok", "(((xs |> len()) > 2) && ok)"},
+		{"xs |> f() + 1 |> g()", "(((xs |> f()) + 1) |> g())"},
+		{"xs |> f()(1) ** 2", "((xs |> f()(1)) ** 2)"},
+		{"x |> h.f(1) |> g()", "((x |> h.f(1)) |> g())"},
+		{"f(x => x + 1)", "f((x) => (x + 1))"},
+		{"(a, b = 1) => a * b", "(a, b = 1) => (a * b)"},
+		{"(x) => x |> f()", "(x) => (x |> f())"},
+		{"(a + b) * c", "((a + b) * c)"},
+		{"p.x + 1", "((p.x) + 1)"},
+		{"a.b.c", "((a.b).c)"},
+		{"-p.x", "(-(p.x))"},
+		{"xs.len() * 2", "(xs.len() * 2)"},
+		{"a.b(1).c[0]", "((a.b(1).c)[0])"},
+		{"p.x = p.y + 1", "(p.x) = ((p.y) + 1)"},
+		{"1.len()", "1.len()"},
 	}
 	for _, tt := range precedenceTests {
 		l := lexer.New(tt.input)
@@ -478,8 +730,8 @@ func TestFunctionLiteralParsing(t *testing.T) {
 		t.Fatalf("function literal parameters wrong. want 2, got=%d\n",
 			len(function.Parameters))
 	}
-	testLiteralExpression(t, function.Parameters[0], "x")
-	testLiteralExpression(t, function.Parameters[1], "y")
+	testLiteralExpression(t, function.Parameters[0].Name, "x")
+	testLiteralExpression(t, function.Parameters[1].Name, "y")
 	if len(function.Body.Statements) != 1 {
 		t.Fatalf("function.Body.Statements has not 1 statements. got=%d\n",
 			len(function.Body.Statements))
@@ -512,10 +764,210 @@ func TestFunctionParameterParsing(t *testing.T) {
 				len(tt.expectedParams), len(function.Parameters))
 		}
 		for i, ident := range tt.expectedParams {
-			testLiteralExpression(t, function.Parameters[i], ident)
+			testLiteralExpression(t, function.Parameters[i].Name, ident)
+		}
+	}
+}
+func TestDefaultAndRestParameters(t *testing.T) {
+	input := "let f = fn(a, b = a * 2, ...rest) { rest };"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	function := program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if function.Name != "f" {
+		t.Errorf("function.Name not %q. got=%q", "f", function.Name)
+	}
+	if len(function.Parameters) != 3 {
+		t.Fatalf("length parameters wrong. want 3, got=%d", len(function.Parameters))
+	}
+	a, b, rest := function.Parameters[0], function.Parameters[1], function.Parameters[2]
+	if a.Default != nil || a.Rest {
+		t.Errorf("a is not a plain parameter. got=%s", a)
+	}
+	if !testInfixExpression(t, b.Default, "a", "*", 2) {
+		return
+	}
+	if !rest.Rest || !testIdentifier(t, rest.Name, "rest") {
+		t.Errorf("rest is not a rest parameter. got=%s", rest)
+	}
+	expected := "let f = fn(a, b = (a * 2), ...rest) rest;"
+	if program.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestParameterErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedCode    string
+		expectedMessage string
+		expectedStart   int
+	}{
+		{"fn(1) {}", parser.ErrInvalidParameter, "expected a parameter name, got INT", 3},
+		{"fn(a, a) {}", parser.ErrDuplicateBinding, "duplicate parameter a", 6},
+		{"fn(a = 1, b) {}", parser.ErrInvalidParameter, "parameter b needs a default value", 10},
+		{"fn(...a, b) {}", parser.ErrInvalidParameter, "rest parameter ...a must be the last parameter", 3},
+		{"f(a: 1, 2)", parser.ErrInvalidArgument, "positional argument 2 cannot follow keyword arguments", 8},
+		{"f(a: 1, a: 2)", parser.ErrInvalidArgument, "keyword argument a given more than once", 8},
+		{"(1) => 2", parser.ErrInvalidParameter, "expected a parameter name, got 1", 1},
+		{"((x)) => x", parser.ErrInvalidParameter, "expected a parameter name, got x", 2},
+		{"(a += 1) => a", parser.ErrInvalidParameter, "expected a parameter name, got a += 1", 1},
+		{"(a, a) => a", parser.ErrDuplicateBinding, "duplicate parameter a", 4},
+		{"(a = 1, b) => a", parser.ErrInvalidParameter, "parameter b needs a default value", 8},
+		{"(a, b) + 1", parser.ErrUnexpectedToken, "expected next token to be ), got , instead", 2},
+		{"(...xs)", parser.ErrNoPrefixParseFn, "no prefix parse function for ... found", 1},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Code != tt.expectedCode {
+			t.Errorf("%q: wrong code. expected=%s, got=%s", tt.input, tt.expectedCode, errors[0].Code)
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, errors[0].Message)
+		}
+		if errors[0].Span.Start.Offset != tt.expectedStart {
+			t.Errorf("%q: wrong start. expected=%d, got=%d", tt.input, tt.expectedStart, errors[0].Span.Start.Offset)
 		}
 	}
 }
+
+func TestFunctionDeclaration(t *testing.T) {
+	input := `/// Adds two numbers.
+fn add(a, b = 1) { a + b }
+fn(x) { x }(1);`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	decl, ok := program.Statements[0].(*ast.FunctionDeclaration)
+	if !ok {
+		t.Fatalf("stmt not *ast.FunctionDeclaration. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, decl.Name, "add") {
+		return
+	}
+	if decl.Function.Name != "add" {
+		t.Errorf("decl.Function.Name not %q. got=%q", "add", decl.Function.Name)
+	}
+	if decl.Doc == nil || decl.Doc.Text() != "Adds two numbers." {
+		t.Errorf("decl.Doc wrong. got=%v", decl.Doc)
+	}
+	if decl.String() != "fn add(a, b = 1) (a + b)" {
+		t.Errorf("decl.String() wrong. got=%q", decl.String())
+	}
+	if _, ok := program.Statements[1].(*ast.ExpressionStatement); !ok {
+		t.Errorf("fn(x) { x }(1) not *ast.ExpressionStatement. got=%T", program.Statements[1])
+	}
+}
+
+func TestFunctionDeclarationWithSemicolon(t *testing.T) {
+	p := parser.New(lexer.New("fn f() { 1 }; f();"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	if _, ok := program.Statements[0].(*ast.FunctionDeclaration); !ok {
+		t.Errorf("stmt not *ast.FunctionDeclaration. got=%T", program.Statements[0])
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expectedBody   string
+	}{
+		{"x => x * 2", []string{"x"}, "(x * 2)"},
+		{"() => 1", []string{}, "1"},
+		{"(a, b = 2, ...rest) => a", []string{"a", "b = 2", "...rest"}, "a"},
+		{"(x) => { let y = x; y }", []string{"x"}, "let y = x;y"},
+		{"(a, b,) => a + b", []string{"a", "b"}, "(a + b)"},
+		{"(f = (x) => x) => f(1)", []string{"f = (x) => x"}, "f(1)"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("%q: exp not *ast.FunctionLiteral. got=%T", tt.input, stmt.Expression)
+		}
+		if !function.Arrow {
+			t.Errorf("%q: function.Arrow is false", tt.input)
+		}
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("%q: length parameters wrong. want %d, got=%d",
+				tt.input, len(tt.expectedParams), len(function.Parameters))
+		}
+		for i, param := range tt.expectedParams {
+			if function.Parameters[i].String() != param {
+				t.Errorf("%q: parameter %d wrong. want %q, got=%q", tt.input, i, param, function.Parameters[i])
+			}
+		}
+		if function.Body.String() != tt.expectedBody {
+			t.Errorf("%q: body wrong. want %q, got=%q", tt.input, tt.expectedBody, function.Body.String())
+		}
+	}
+}
+
+// TestDeeplyNestedParentheses guards against parentheses being scanned
+// more than once while deciding whether they start an arrow function.
+func TestDeeplyNestedParentheses(t *testing.T) {
+	depth := 4000
+	input := strings.Repeat("(a + ", depth) + "a" + strings.Repeat(")", depth)
+
+	start := time.Now()
+	p := parser.New(lexer.New(input))
+	p.ParseProgram()
+	checkParserErrors(t, p)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("parsing %d nested parentheses took %s", depth, elapsed)
+	}
+}
+
+func TestArrowInMatchGuard(t *testing.T) {
+	input := "match (v) { n if ok => n, n if any(xs, x => x > n) => 0, _ => 1 }"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	match := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+	if !testIdentifier(t, match.Arms[0].Guard, "ok") {
+		return
+	}
+	if match.Arms[1].Guard.String() != "any(xs, (x) => (x > n))" {
+		t.Errorf("guard wrong. got=%q", match.Arms[1].Guard.String())
+	}
+}
+
+func TestPipeErrors(t *testing.T) {
+	p := parser.New(lexer.New("xs |> len + 1"))
+	p.ParseProgram()
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("wrong number of errors. expected=1, got=%d (%v)", len(errors), errors)
+	}
+	if errors[0].Code != parser.ErrInvalidPipe {
+		t.Errorf("wrong code. expected=%s, got=%s", parser.ErrInvalidPipe, errors[0].Code)
+	}
+	if errors[0].Message != "right side of |> must be a call, got len" {
+		t.Errorf("wrong message. got=%q", errors[0].Message)
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 	l := lexer.New(input)
@@ -546,3 +998,733 @@ func TestCallExpressionParsing(t *testing.T) {
 	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
 	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
 }
+
+func TestKeywordArguments(t *testing.T) {
+	input := "f(x, b: 5, c: 1 + 2);"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+	testIdentifier(t, exp.Arguments[0], "x")
+	keyword, ok := exp.Arguments[2].(*ast.KeywordArgument)
+	if !ok {
+		t.Fatalf("exp.Arguments[2] not *ast.KeywordArgument. got=%T", exp.Arguments[2])
+	}
+	testIdentifier(t, keyword.Name, "c")
+	testInfixExpression(t, keyword.Value, 1, "+", 2)
+	if exp.String() != "f(x, b: 5, c: (1 + 2))" {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
+	}
+}
+
+func TestMethodCallExpression(t *testing.T) {
+	input := "xs.push(1, at: 0);"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	exp, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MethodCallExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MethodCallExpression. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	testIdentifier(t, exp.Object, "xs")
+	testIdentifier(t, exp.Method, "push")
+	if len(exp.Arguments) != 2 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+	if _, ok := exp.Arguments[1].(*ast.KeywordArgument); !ok {
+		t.Errorf("exp.Arguments[1] not *ast.KeywordArgument. got=%T", exp.Arguments[1])
+	}
+	if exp.Pos().Offset != 0 || exp.End().Offset != 17 {
+		t.Errorf("span wrong. got=[%d,%d)", exp.Pos().Offset, exp.End().Offset)
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := `let x = 1 + 2;
+add(x, fn(y) { y });`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+	letStmt := program.Statements[0].(*ast.LetStatement)
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	tests := []struct {
+		node        ast.Node
+		start, end  string
+		startOffset int
+		endOffset   int
+	}{
+		{letStmt, "1:1", "1:14", 0, 13},
+		{letStmt.Name, "1:5", "1:6", 4, 5},
+		{letStmt.Value, "1:9", "1:14", 8, 13},
+		{call, "2:1", "2:20", 15, 34},
+		{call.Arguments[1], "2:8", "2:19", 22, 33},
+		{program, "1:1", "2:20", 0, 34},
+	}
+	for i, tt := range tests {
+		if got := tt.node.Pos().String(); got != tt.start {
+			t.Errorf("tests[%d] - Pos() wrong. expected=%s, got=%s", i, tt.start, got)
+		}
+		if got := tt.node.End().String(); got != tt.end {
+			t.Errorf("tests[%d] - End() wrong. expected=%s, got=%s", i, tt.end, got)
+		}
+		if tt.node.Pos().Offset != tt.startOffset || tt.node.End().Offset != tt.endOffset {
+			t.Errorf("tests[%d] - span wrong. expected=[%d,%d), got=[%d,%d)", i,
+				tt.startOffset, tt.endOffset, tt.node.Pos().Offset, tt.node.End().Offset)
+		}
+	}
+}
+
+func TestParserDiagnostics(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedCode    string
+		expectedMessage string
+		expectedStart   int
+		expectedEnd     int
+	}{
+		{"let x = (5;", parser.ErrUnexpectedToken, "expected next token to be ), got ; instead", 10, 11},
+		{"let 5 = x;", parser.ErrUnexpectedToken, "expected next token to be IDENT, got INT instead", 4, 5},
+		{"* 5;", parser.ErrNoPrefixParseFn, "no prefix parse function for * found", 0, 1},
+		{"1e999;", parser.ErrInvalidFloat, `could not parse "1e999" as float`, 0, 5},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: expected a parser error", tt.input)
+			continue
+		}
+		d := errors[0]
+		if d.Severity != diagnostic.Error {
+			t.Errorf("input %q: wrong severity. got=%s", tt.input, d.Severity)
+		}
+		if d.Code != tt.expectedCode {
+			t.Errorf("input %q: wrong code. expected=%s, got=%s", tt.input, tt.expectedCode, d.Code)
+		}
+		if d.Message != tt.expectedMessage {
+			t.Errorf("input %q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, d.Message)
+		}
+		if d.Span.Start.Offset != tt.expectedStart || d.Span.End.Offset != tt.expectedEnd {
+			t.Errorf("input %q: wrong span. expected=[%d,%d), got=[%d,%d)", tt.input,
+				tt.expectedStart, tt.expectedEnd, d.Span.Start.Offset, d.Span.End.Offset)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	input := `let x = (5;
+let = 10;
+let f = fn(a) { let b = a +; b };
+if (x { y };
+let z = 3;`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	expectedErrorLines := []int{1, 2, 3, 4}
+	errors := p.Errors()
+	if len(errors) != len(expectedErrorLines) {
+		for _, d := range errors {
+			t.Errorf("parser error: %q", d)
+		}
+		t.Fatalf("wrong number of errors. expected=%d, got=%d",
+			len(expectedErrorLines), len(errors))
+	}
+	for i, line := range expectedErrorLines {
+		if errors[i].Span.Start.Line != line {
+			t.Errorf("errors[%d] on wrong line. expected=%d, got=%d",
+				i, line, errors[i].Span.Start.Line)
+		}
+	}
+
+	expectedStatements := []string{
+		"*ast.LetStatement",
+		"*ast.BadStatement",
+		"*ast.LetStatement",
+		"*ast.ExpressionStatement",
+		"*ast.LetStatement",
+	}
+	if len(program.Statements) != len(expectedStatements) {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d",
+			len(expectedStatements), len(program.Statements))
+	}
+	for i, expected := range expectedStatements {
+		if got := fmt.Sprintf("%T", program.Statements[i]); got != expected {
+			t.Errorf("Statements[%d] wrong type. expected=%s, got=%s", i, expected, got)
+		}
+	}
+
+	letX := program.Statements[0].(*ast.LetStatement)
+	if _, ok := letX.Value.(*ast.BadExpression); !ok {
+		t.Errorf("letX.Value is not ast.BadExpression. got=%T", letX.Value)
+	}
+	fn := program.Statements[2].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if len(fn.Body.Statements) != 2 {
+		t.Errorf("function body should keep both statements. got=%d", len(fn.Body.Statements))
+	}
+	if !testLetStatement(t, program.Statements[4], "z") {
+		return
+	}
+}
+
+func TestErrorAtClosingBrace(t *testing.T) {
+	input := `let f = fn() { 1 + }; let g = 2;`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 1 {
+		t.Fatalf("expected exactly 1 error. got=%d (%v)", len(p.Errors()), p.Errors())
+	}
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+	testLetStatement(t, program.Statements[1], "g")
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 5;", "x = 5"},
+		{"x += 1 + 2;", "x += (1 + 2)"},
+		{"a = b = c;", "a = b = c"},
+		{"xs[0] *= 2;", "(xs[0]) *= 2"},
+		{"h[\"k\"] %= 3;", "(h[\"k\"]) %= 3"},
+		{"x = y || z;", "x = (y || z)"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.AssignExpression); !ok {
+			t.Fatalf("%q: exp not *ast.AssignExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidAssignTargets(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedStart   int
+		expectedEnd     int
+	}{
+		{"5 = x;", "cannot assign to 5", 0, 1},
+		{"a + b += 1;", "cannot assign to (a + b)", 0, 5},
+		{"f() = 1;", "cannot assign to f()", 0, 3},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		d := errors[0]
+		if d.Code != parser.ErrInvalidAssign {
+			t.Errorf("%q: wrong code. expected=%s, got=%s", tt.input, parser.ErrInvalidAssign, d.Code)
+		}
+		if d.Message != tt.expectedMessage {
+			t.Errorf("%q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, d.Message)
+		}
+		if d.Span.Start.Offset != tt.expectedStart || d.Span.End.Offset != tt.expectedEnd {
+			t.Errorf("%q: wrong span. expected=[%d,%d), got=[%d,%d)", tt.input,
+				tt.expectedStart, tt.expectedEnd, d.Span.Start.Offset, d.Span.End.Offset)
+		}
+	}
+}
+
+func TestWhileExpression(t *testing.T) {
+	input := `while (x < 10) { x += 1; if (x == 5) { break; } continue; }`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program has wrong number of statements. got=%d", len(program.Statements))
+	}
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	loop, ok := stmt.Expression.(*ast.WhileExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.WhileExpression. got=%T", stmt.Expression)
+	}
+	if !testInfixExpression(t, loop.Condition, "x", "<", 10) {
+		return
+	}
+	if len(loop.Body.Statements) != 3 {
+		t.Fatalf("body has wrong number of statements. got=%d", len(loop.Body.Statements))
+	}
+	if _, ok := loop.Body.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("body.Statements[2] not *ast.ContinueStatement. got=%T", loop.Body.Statements[2])
+	}
+}
+
+func TestForExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (let i = 0; i < n; i += 1) { f(i); }", "for (let i = 0; (i < n); i += 1) f(i)"},
+		{"for (i = 0; i < n; i = i + 1) { }", "for (i = 0; (i < n); i = (i + 1)) "},
+		{"for (;;) { break; }", "for (; ; ) break;"},
+		{"for (let i = 0;; ) { break }", "for (let i = 0; ; ) break;"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program has wrong number of statements. got=%d", tt.input, len(program.Statements))
+		}
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.ForExpression); !ok {
+			t.Fatalf("%q: exp not *ast.ForExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestForInExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKey   string
+		expectedValue string
+		expected      string
+	}{
+		{"for (x in xs) { f(x) }", "", "x", "for (x in xs) f(x)"},
+		{"for (k, v in {1: 2}) { break; }", "k", "v", "for (k, v in {1: 2}) break;"},
+		{"for (c in \"abc\") { }", "", "c", "for (c in \"abc\") "},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		loop, ok := stmt.Expression.(*ast.ForInExpression)
+		if !ok {
+			t.Fatalf("%q: exp not *ast.ForInExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if tt.expectedKey == "" && loop.Key != nil {
+			t.Errorf("%q: loop.Key not nil. got=%s", tt.input, loop.Key)
+		}
+		if tt.expectedKey != "" && !testIdentifier(t, loop.Key, tt.expectedKey) {
+			return
+		}
+		if !testIdentifier(t, loop.Value, tt.expectedValue) {
+			return
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestForInErrors(t *testing.T) {
+	p := parser.New(lexer.New("for (k, 1 in xs) { }"))
+	p.ParseProgram()
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("wrong number of errors. expected=1, got=%d (%v)", len(errors), errors)
+	}
+	if errors[0].Message != "expected next token to be IDENT, got INT instead" {
+		t.Errorf("wrong message. got=%q", errors[0].Message)
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedStart   int
+	}{
+		{"break;", "break outside of a loop", 0},
+		{"if (true) { continue; }", "continue outside of a loop", 12},
+		{"while (true) { let f = fn() { break; }; }", "break outside of a loop", 30},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Code != parser.ErrOutsideLoop {
+			t.Errorf("%q: wrong code. expected=%s, got=%s", tt.input, parser.ErrOutsideLoop, errors[0].Code)
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, errors[0].Message)
+		}
+		if errors[0].Span.Start.Offset != tt.expectedStart {
+			t.Errorf("%q: wrong start. expected=%d, got=%d", tt.input, tt.expectedStart, errors[0].Span.Start.Offset)
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (v) {
+		0 => "zero",
+		-1.5 => "negative",
+		[a, b] => a + b,
+		[first, ...rest] => rest,
+		{"k": x, 1: [y]} => x,
+		n if n > 10 => { n * 2 }
+		_ => null,
+	}`
+	expectedPatterns := []struct {
+		patternType string
+		pattern     string
+	}{
+		{"*ast.LiteralPattern", "0"},
+		{"*ast.LiteralPattern", "(-1.5)"},
+		{"*ast.ArrayPattern", "[a, b]"},
+		{"*ast.ArrayPattern", "[first, ...rest]"},
+		{"*ast.HashPattern", "{\"k\": x, 1: [y]}"},
+		{"*ast.BindingPattern", "n"},
+		{"*ast.WildcardPattern", "_"},
+	}
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	match, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, match.Subject, "v") {
+		return
+	}
+	if len(match.Arms) != len(expectedPatterns) {
+		t.Fatalf("wrong number of arms. expected=%d, got=%d", len(expectedPatterns), len(match.Arms))
+	}
+	for i, tt := range expectedPatterns {
+		pattern := match.Arms[i].Pattern
+		if fmt.Sprintf("%T", pattern) != tt.patternType {
+			t.Errorf("arms[%d]: pattern not %s. got=%T", i, tt.patternType, pattern)
+		}
+		if pattern.String() != tt.pattern {
+			t.Errorf("arms[%d]: expected pattern %q, got=%q", i, tt.pattern, pattern.String())
+		}
+	}
+	guarded := match.Arms[5]
+	if !testInfixExpression(t, guarded.Guard, "n", ">", 10) {
+		return
+	}
+	if _, ok := guarded.Body.(*ast.BlockStatement); !ok {
+		t.Errorf("arms[5].Body not *ast.BlockStatement. got=%T", guarded.Body)
+	}
+	if len(p.Warnings()) != 0 {
+		t.Errorf("expected no warnings, got=%v", p.Warnings())
+	}
+}
+
+func TestMatchPatternErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedStart   int
+	}{
+		{"match (v) { x + 1 => 0 }", "expected next token to be =>, got + instead", 14},
+		{"match (v) { (x) => 0 }", "expected a pattern, got (", 12},
+		{"match (v) { -x => 0 }", "expected a number after - in a pattern, got IDENT", 13},
+		{"match (v) { {1.5: x} => 0 }", "expected a name, string, integer or boolean key in a hash pattern, got FLOAT", 13},
+		{"match (v) { [...rest, x] => 0 }", "...rest must be the last element of an array pattern", 22},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("%q: wrong number of errors. expected=1, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, errors[0].Message)
+		}
+		if errors[0].Span.Start.Offset != tt.expectedStart {
+			t.Errorf("%q: wrong start. expected=%d, got=%d", tt.input, tt.expectedStart, errors[0].Span.Start.Offset)
+		}
+	}
+}
+
+func TestMatchWarnings(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCodes    []string
+		expectedMessages []string
+	}{
+		{"match (v) { 1 => 1, _ => 2 }", nil, nil},
+		{"match (v) { true => 1, false => 2 }", nil, nil},
+		{"match (v) { 1 => 1, 2 => 2 }",
+			[]string{parser.WarnNonExhaustive},
+			[]string{"match is not exhaustive"}},
+		{"match (v) { n if n > 1 => 1, [] => 2 }",
+			[]string{parser.WarnNonExhaustive},
+			[]string{"match is not exhaustive"}},
+		{"match (v) { x => 1, 2 => 2 }",
+			[]string{parser.WarnUnreachableArm},
+			[]string{"unreachable match arm"}},
+		{"match (v) { 1 if ok => 1, 1 => 2, 1 => 3, _ => 4 }",
+			[]string{parser.WarnUnreachableArm},
+			[]string{"unreachable match arm"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		checkParserErrors(t, p)
+		warnings := p.Warnings()
+		if len(warnings) != len(tt.expectedCodes) {
+			t.Fatalf("%q: wrong number of warnings. expected=%d, got=%d (%v)",
+				tt.input, len(tt.expectedCodes), len(warnings), warnings)
+		}
+		for i, w := range warnings {
+			if w.Severity != diagnostic.Warning {
+				t.Errorf("%q: wrong severity. got=%s", tt.input, w.Severity)
+			}
+			if w.Code != tt.expectedCodes[i] || w.Message != tt.expectedMessages[i] {
+				t.Errorf("%q: expected %s %q, got=%s %q", tt.input,
+					tt.expectedCodes[i], tt.expectedMessages[i], w.Code, w.Message)
+			}
+		}
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello\tworld";`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != "hello\tworld" {
+		t.Errorf("literal.Value not %q. got=%q", "hello\tworld", literal.Value)
+	}
+	if literal.String() != `"hello\tworld"` {
+		t.Errorf("literal.String() not %q. got=%q", `"hello\tworld"`, literal.String())
+	}
+}
+
+func TestLexerErrorsAreReportedOnce(t *testing.T) {
+	input := `let a = "unterminated;
+let b = @;`
+	l := lexer.New(input)
+	p := parser.New(l)
+	p.ParseProgram()
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected exactly 1 error. got=%d (%v)", len(errors), errors)
+	}
+	if errors[0].Code != lexer.ErrUnterminatedString {
+		t.Errorf("wrong error code. expected=%s, got=%s", lexer.ErrUnterminatedString, errors[0].Code)
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"[1, 2 * 2, 3 + 3]", 3},
+		{"[1, 2 * 2, 3 + 3,]", 3},
+		{"[]", 0},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+		}
+		if len(array.Elements) != tt.expected {
+			t.Fatalf("len(array.Elements) not %d. got=%d", tt.expected, len(array.Elements))
+		}
+		if tt.expected == 3 {
+			testIntegerLiteral(t, array.Elements[0], 1)
+			testInfixExpression(t, array.Elements[1], 2, "*", 2)
+			testInfixExpression(t, array.Elements[2], 3, "+", 3)
+		}
+	}
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	input := "myArray[1 + 1]"
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, indexExp.Left, "myArray") {
+		return
+	}
+	if !testInfixExpression(t, indexExp.Index, 1, "+", 1) {
+		return
+	}
+	if indexExp.End().Offset != len(input) {
+		t.Errorf("indexExp.End() wrong. expected offset %d, got=%d", len(input), indexExp.End().Offset)
+	}
+}
+
+func TestCallArgumentsTrailingComma(t *testing.T) {
+	l := lexer.New("add(1, 2,);")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if len(call.Arguments) != 2 {
+		t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+	}
+}
+
+func TestParsingHashLiterals(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+	expected := []struct {
+		key   string
+		value int64
+	}{
+		{"one", 1},
+		{"two", 2},
+		{"three", 3},
+	}
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	for i, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
+			continue
+		}
+		if literal.Value != expected[i].key {
+			t.Errorf("Pairs[%d] out of order. expected key %q, got=%q", i, expected[i].key, literal.Value)
+		}
+		testIntegerLiteral(t, pair.Value, expected[i].value)
+	}
+}
+
+func TestParsingHashLiteralsWithMixedKeys(t *testing.T) {
+	input := `{"k": 1 + 1, 1: true, false: "no",}`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	hash := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.HashLiteral)
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	testInfixExpression(t, hash.Pairs[0].Value, 1, "+", 1)
+	testIntegerLiteral(t, hash.Pairs[1].Key, 1)
+	testBooleanLiteral(t, hash.Pairs[1].Value, true)
+	testBooleanLiteral(t, hash.Pairs[2].Key, false)
+	if hash.String() != `{"k": (1 + 1), 1: true, false: "no"}` {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	l := lexer.New("{}")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	hash, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", program.Statements[0])
+	}
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestHashLiteralDoesNotReplaceBlocks(t *testing.T) {
+	input := `if (x) { y } else { let h = {"a": 1}; h }`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if len(exp.Alternative.Statements) != 2 {
+		t.Fatalf("alternative is not 2 statements. got=%d", len(exp.Alternative.Statements))
+	}
+	let := exp.Alternative.Statements[0].(*ast.LetStatement)
+	if _, ok := let.Value.(*ast.HashLiteral); !ok {
+		t.Errorf("let value is not ast.HashLiteral. got=%T", let.Value)
+	}
+}
+
+func TestDocCommentsOnLetStatements(t *testing.T) {
+	input := `/// The answer.
+/// Computed at great length.
+let answer = 42;
+// an ordinary comment
+let other = 1;`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	answer := program.Statements[0].(*ast.LetStatement)
+	if answer.Doc == nil {
+		t.Fatalf("answer.Doc is nil")
+	}
+	if answer.Doc.Text() != "The answer.\nComputed at great length." {
+		t.Errorf("answer.Doc.Text() wrong. got=%q", answer.Doc.Text())
+	}
+	if answer.Doc.Pos().Line != 1 || answer.Doc.End().Line != 2 {
+		t.Errorf("answer.Doc span wrong. got=%s-%s", answer.Doc.Pos(), answer.Doc.End())
+	}
+	other := program.Statements[1].(*ast.LetStatement)
+	if other.Doc != nil {
+		t.Errorf("other.Doc is not nil. got=%q", other.Doc.Text())
+	}
+}
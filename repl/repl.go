@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/TusharAbhinav/monkey/diagnostic"
+	"github.com/TusharAbhinav/monkey/evaluator"
 	"github.com/TusharAbhinav/monkey/lexer"
+	"github.com/TusharAbhinav/monkey/object"
 	"github.com/TusharAbhinav/monkey/parser"
 )
 
@@ -17,22 +20,29 @@ var PROMPT = ">> "
 
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
+	env := object.NewEnvironment()
 	for {
-		fmt.Printf(PROMPT)
+		fmt.Fprint(out, PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
 			return
 		}
 		line := scanner.Text()
-		l := lexer.New(line)
+		l := lexer.NewWithFilename("repl", line)
 		p := parser.New(l)
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+			printParserErrors(out, line, p.Errors())
 			continue
 		}
-		io.WriteString(out, program.String())
-		io.WriteString(out, "\n")
+		if warnings := p.Warnings(); len(warnings) != 0 {
+			diagnostic.RenderAll(out, line, warnings)
+		}
+		evaluated := evaluator.Eval(program, env)
+		if evaluated != nil {
+			io.WriteString(out, evaluated.Inspect())
+			io.WriteString(out, "\n")
+		}
 	}
 }
 
@@ -51,11 +61,9 @@ const MONKEY_FACE = `
                 __,__
 `
 
-func printParserErrors(out io.Writer, errors []string) {
+func printParserErrors(out io.Writer, source string, errors []diagnostic.Diagnostic) {
 	io.WriteString(out, MONKEY_FACE)
 	io.WriteString(out, "Woops! We ran into some monkey business here!\n")
 	io.WriteString(out, " parser errors:\n")
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
-	}
+	diagnostic.RenderAll(out, source, errors)
 }
@@ -1,35 +1,94 @@
 package Token
 
+import "fmt"
+
 type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // position of the first character of the token
+	End     Position // position immediately after the last character of the token
+	Doc     []Token  // DOC_COMMENT tokens directly preceding this token
+}
+
+// Position describes a location in the source text.
+// Line and Column start at 1, Offset is the 0-based byte offset.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid reports whether the position was set by the lexer.
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+// String returns the position as file:line:column, or line:column
+// when there is no file name.
+func (p Position) String() string {
+	s := fmt.Sprintf("%d:%d", p.Line, p.Column)
+	if p.Filename != "" {
+		s = p.Filename + ":" + s
+	}
+	return s
 }
 
 const (
-	ILLEGAL = "ILLEGAL"
-	EOF     = "EOF"
+	ILLEGAL     = "ILLEGAL"
+	EOF         = "EOF"
+	DOC_COMMENT = "DOC_COMMENT" // /// text, only ever found in Token.Doc
 	// Identifiers + literals
-	IDENT = "IDENT" // add, foobar, x, y, ...
-	INT   = "INT"   // 1343456
+	IDENT    = "IDENT"    // add, foobar, x, y, ...
+	INT      = "INT"      // 1343456
+	STRING   = "STRING"   // "foo bar"
+	FLOAT    = "FLOAT"    // 3.14, 1e-9
+	RATIONAL = "RATIONAL" // 3r
+	DECIMAL  = "DECIMAL"  // 12.50d
 	// Operators
-	ASSIGN   = "="
-	PLUS     = "+"
-	EQ       = "=="
-	NOT_EQ   = "!="
-	MINUS    = "-"
-	BANG     = "!"
-	ASTERISK = "*"
-	SLASH    = "/"
-	LT       = "<"
-	GT       = ">"
+	ASSIGN    = "="
+	PLUS      = "+"
+	EQ        = "=="
+	NOT_EQ    = "!="
+	MINUS     = "-"
+	BANG      = "!"
+	ASTERISK  = "*"
+	SLASH     = "/"
+	LT        = "<"
+	GT        = ">"
+	LT_EQ     = "<="
+	GT_EQ     = ">="
+	AND       = "&&"
+	OR        = "||"
+	MODULO    = "%"
+	POWER     = "**"
+	BIT_AND   = "&"
+	BIT_OR    = "|"
+	BIT_XOR   = "^"
+	BIT_NOT   = "~"
+	SHL       = "<<"
+	SHR       = ">>"
+	DOTDOT    = ".."
+	DOTDOT_EQ = "..="
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
+	PIPE      = "|>"
+	// Compound assignment operators
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	MODULO_ASSIGN   = "%="
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	DOT       = "."
 	LPAREN    = "("
 	RPAREN    = ")"
 	LBRACE    = "{"
 	RBRACE    = "}"
+	LBRACKET  = "["
+	RBRACKET  = "]"
 	// Keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
@@ -38,16 +97,28 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IN       = "IN"
+	MATCH    = "MATCH"
 )
 
 var keywords = map[string]TokenType{
-	"let":    LET,
-	"fn":     FUNCTION,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"let":      LET,
+	"fn":       FUNCTION,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"in":       IN,
+	"match":    MATCH,
 }
 
 func ReadKeyword(input string) TokenType {